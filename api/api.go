@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+
+	"traderkit-server/database"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+// DB is the subset of *pgxpool.Pool used by the HTTP handlers, which allows it to be stubbed in tests.
+type DB interface {
+	Ping(ctx context.Context) error
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// New creates the Fiber app serving the HTTP API, with its handlers querying the given database. The readiness check
+// expects every migration in `migrations` to have been applied, defaulting to the embedded database.Migrations if nil.
+func New(db DB, migrations fs.FS) *fiber.App {
+	if migrations == nil {
+		migrations = database.Migrations
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: errorHandler})
+	h := handlers{db: db, migrations: migrations}
+
+	app.Get("/healthz", h.getHealthz)
+	app.Get("/readyz", h.getReadyz)
+	app.Get("/bars", h.getBars)
+	app.Get("/symbols/latest", h.getLatestSymbols)
+
+	return app
+}
+
+// handlers holds the dependencies shared by the HTTP handlers.
+type handlers struct {
+	db         DB
+	migrations fs.FS
+}
+
+// errorHandler responds to errors returned by the handlers. A *fiber.Error, such as a 400 for invalid parameters or a
+// 503 from a probe, is sent as-is. Any other error is logged and answered with a generic 500, so that internal details
+// such as database error messages are never exposed to clients.
+func errorHandler(c *fiber.Ctx, err error) error {
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return fiber.DefaultErrorHandler(c, fe)
+	}
+
+	slog.ErrorContext(c.UserContext(), "request failed", "method", c.Method(), "path", c.Path(), "error", err)
+
+	return fiber.DefaultErrorHandler(c, fiber.ErrInternalServerError)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestErrorHandler_HidesInternalErrors ensures that a failed database query is logged, but answered with a generic 500
+// that doesn't reveal the underlying error to the client.
+func TestErrorHandler_HidesInternalErrors(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	for _, path := range []string{
+		"/symbols/latest",
+		"/bars?symbol=AAPL&from=2025-07-10T13:30:00Z&to=2025-07-10T20:00:00Z",
+	} {
+		logs.Reset()
+		resp, err := New(stubDB{}, nil).Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+
+		if resp.StatusCode != http.StatusInternalServerError || string(body) != "Internal Server Error" {
+			t.Errorf("Expected a generic 500 for %s, got %d: %s", path, resp.StatusCode, body)
+		}
+		if !strings.Contains(logs.String(), "query not supported by stub") {
+			t.Errorf("Expected the error for %s to be logged, got: %s", path, logs.String())
+		}
+	}
+}
+
+// TestErrorHandler_KeepsFiberErrors ensures that deliberate client errors are still sent with their status and message.
+func TestErrorHandler_KeepsFiberErrors(t *testing.T) {
+	resp, err := New(nil, nil).Test(httptest.NewRequest(http.MethodGet, "/bars?symbol=AAPL", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(body), "from") {
+		t.Errorf("Expected a 400 describing the invalid parameter, got %d: %s", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+// symbolPattern matches the tickers accepted by the bars endpoint, such as `AAPL` or `BRK.A`.
+var symbolPattern = regexp.MustCompile(`^[A-Z][A-Z0-9.]{0,9}$`)
+
+// maxBarsRange is the widest span between `from` and `to` that may be requested for each interval, which bounds the
+// number of bars returned by a single request.
+var maxBarsRange = map[string]time.Duration{
+	"minute": 7 * 24 * time.Hour,
+	"hour":   92 * 24 * time.Hour,
+	"day":    5 * 366 * 24 * time.Hour,
+}
+
+// bar is a single OHLCV bar as returned by the bars endpoint.
+type bar struct {
+	Time         time.Time `json:"t"`
+	Open         float64   `json:"o"`
+	High         float64   `json:"h"`
+	Low          float64   `json:"l"`
+	Close        float64   `json:"c"`
+	Volume       float64   `json:"v"`
+	Transactions int64     `json:"n"`
+}
+
+// barsQuery aggregates the minute bars of a symbol into buckets of the requested interval, measured in Eastern Time so
+// that daily bars align with the trading day. The open and close are those of the first and last bars in each bucket.
+const barsQuery = `
+	SELECT date_trunc($4, ts, 'America/New_York') AS bucket,
+		(array_agg(o ORDER BY ts))[1]::float8,
+		max(h)::float8,
+		min(l)::float8,
+		(array_agg(c ORDER BY ts DESC))[1]::float8,
+		sum(v)::float8,
+		sum(txns)::bigint
+	FROM bars
+	WHERE s_id = $1 AND ts >= $2 AND ts <= $3
+	GROUP BY bucket
+	ORDER BY bucket`
+
+// getBars handles `GET /bars`, returning the bars of a `symbol` between the inclusive RFC 3339 timestamps `from` and
+// `to`, aggregated to the given `interval` of `minute` (the default), `hour`, or `day`.
+func (h handlers) getBars(c *fiber.Ctx) error {
+	symbol := strings.ToUpper(c.Query("symbol"))
+	if !symbolPattern.MatchString(symbol) {
+		return fiber.NewError(fiber.StatusBadRequest, "symbol must be a valid ticker")
+	}
+
+	interval := c.Query("interval", "minute")
+	maxRange, ok := maxBarsRange[interval]
+	if !ok {
+		return fiber.NewError(fiber.StatusBadRequest, "interval must be one of minute, hour, or day")
+	}
+
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "from must be an RFC 3339 timestamp")
+	}
+	to, err := time.Parse(time.RFC3339, c.Query("to"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "to must be an RFC 3339 timestamp")
+	}
+	if from.After(to) {
+		return fiber.NewError(fiber.StatusBadRequest, "from must not be after to")
+	}
+	if to.Sub(from) > maxRange {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("range must not exceed %s for %s bars", maxRange,
+			interval))
+	}
+
+	rows, err := h.db.Query(c.UserContext(), barsQuery, symbol, from, to, interval)
+	if err != nil {
+		return fmt.Errorf("unable to query bars: %w", err)
+	}
+
+	bars, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (bar, error) {
+		var b bar
+		err := row.Scan(&b.Time, &b.Open, &b.High, &b.Low, &b.Close, &b.Volume, &b.Transactions)
+		return b, err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to read bars: %w", err)
+	}
+
+	if bars == nil {
+		bars = []bar{}
+	}
+
+	return c.JSON(bars)
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"traderkit-server/database/databasetest"
+)
+
+// getBarsResponse performs a `GET /bars` request against the app with the given query parameters.
+func getBarsResponse(t *testing.T, db DB, params url.Values) *http.Response {
+	t.Helper()
+
+	resp, err := New(db, nil).Test(httptest.NewRequest(http.MethodGet, "/bars?"+params.Encode(), nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	return resp
+}
+
+// TestGetBars_RejectsInvalidParams ensures that malformed or out-of-range query parameters are rejected with a 400
+// before the database is queried.
+func TestGetBars_RejectsInvalidParams(t *testing.T) {
+	valid := url.Values{
+		"symbol": {"AAPL"},
+		"from":   {"2025-07-10T13:30:00Z"},
+		"to":     {"2025-07-10T20:00:00Z"},
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"missing symbol", "symbol", ""},
+		{"invalid symbol", "symbol", "AAPL; DROP TABLE bars"},
+		{"invalid interval", "interval", "week"},
+		{"invalid from", "from", "yesterday"},
+		{"invalid to", "to", "2025-07-10"},
+		{"from after to", "from", "2025-07-11T13:30:00Z"},
+		{"range too wide", "from", "2025-06-01T13:30:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := url.Values{}
+			for k, v := range valid {
+				params[k] = v
+			}
+			params.Set(tt.key, tt.value)
+
+			// A nil database would panic if queried, so a 400 confirms validation happens first.
+			resp := getBarsResponse(t, nil, params)
+			if resp.StatusCode != http.StatusBadRequest {
+				body, _ := io.ReadAll(resp.Body)
+				t.Errorf("Expected status 400, got %d: %s", resp.StatusCode, body)
+			}
+		})
+	}
+}
+
+// TestGetBars_ReturnsAggregatedBars ensures that minute bars are returned for a symbol within the requested range, and
+// are aggregated into hourly bars with the correct open, high, low, close, volume, and transactions.
+func TestGetBars_ReturnsAggregatedBars(t *testing.T) {
+	pool := databasetest.NewPool(t)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `CREATE TABLE bars (
+		s_id TEXT, ts TIMESTAMPTZ, o NUMERIC, h NUMERIC, l NUMERIC, c NUMERIC, v NUMERIC, txns BIGINT,
+		PRIMARY KEY (s_id, ts))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = pool.Exec(ctx, `INSERT INTO bars VALUES
+		('AAPL', '2025-07-10T14:00:00Z', 10, 12, 9, 11, 100, 5),
+		('AAPL', '2025-07-10T14:01:00Z', 11, 15, 10, 14, 200, 7),
+		('AAPL', '2025-07-10T15:00:00Z', 14, 14, 13, 13, 50, 1),
+		('MSFT', '2025-07-10T14:00:00Z', 50, 51, 49, 50, 999, 9)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := url.Values{
+		"symbol":   {"aapl"},
+		"from":     {"2025-07-10T13:30:00Z"},
+		"to":       {"2025-07-10T20:00:00Z"},
+		"interval": {"hour"},
+	}
+	resp := getBarsResponse(t, pool, params)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("Expected status 200, got %d: %s", resp.StatusCode, body)
+	}
+
+	var bars []bar
+	if err := json.NewDecoder(resp.Body).Decode(&bars); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []bar{
+		{time.Date(2025, 7, 10, 14, 0, 0, 0, time.UTC), 10, 15, 9, 14, 300, 12},
+		{time.Date(2025, 7, 10, 15, 0, 0, 0, time.UTC), 14, 14, 13, 13, 50, 1},
+	}
+	if len(bars) != len(expected) {
+		t.Fatalf("Expected %d bars, got %d: %+v", len(expected), len(bars), bars)
+	}
+	for i := range expected {
+		if !bars[i].Time.Equal(expected[i].Time) || bars[i].Open != expected[i].Open ||
+			bars[i].High != expected[i].High || bars[i].Low != expected[i].Low ||
+			bars[i].Close != expected[i].Close || bars[i].Volume != expected[i].Volume ||
+			bars[i].Transactions != expected[i].Transactions {
+			t.Errorf("Expected bar %d to be %+v, got %+v", i, expected[i], bars[i])
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+
+	"traderkit-server/database"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// getHealthz handles `GET /healthz`, responding with 200 if the database can be reached and 503 otherwise. This is
+// intended for use as a liveness probe.
+func (h handlers) getHealthz(c *fiber.Ctx) error {
+	if err := h.db.Ping(c.UserContext()); err != nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "database is unreachable")
+	}
+
+	return c.SendString("ok")
+}
+
+// getReadyz handles `GET /readyz`, which in addition to the checks of getHealthz requires that every migration has been
+// applied and that at least one bar has been ingested before responding with 200. This is intended for use as a
+// readiness probe.
+func (h handlers) getReadyz(c *fiber.Ctx) error {
+	if err := h.db.Ping(c.UserContext()); err != nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "database is unreachable")
+	}
+
+	pending, err := database.PendingMigrations(h.db, h.migrations)
+	if err != nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "unable to check migrations")
+	}
+	if len(pending) > 0 {
+		return fiber.NewError(fiber.StatusServiceUnavailable, fmt.Sprintf("%d migrations are pending", len(pending)))
+	}
+
+	var hasBars bool
+	if err := h.db.QueryRow(c.UserContext(), "SELECT EXISTS (SELECT 1 FROM bars)").Scan(&hasBars); err != nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "unable to check for bars")
+	}
+	if !hasBars {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "no bars have been ingested")
+	}
+
+	return c.SendString("ok")
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"traderkit-server/database/databasetest"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// stubDB is a DB whose Ping succeeds or fails on demand, and whose queries always fail.
+type stubDB struct {
+	pingErr error
+}
+
+func (s stubDB) Ping(context.Context) error {
+	return s.pingErr
+}
+
+func (s stubDB) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return nil, errors.New("query not supported by stub")
+}
+
+func (s stubDB) QueryRow(context.Context, string, ...any) pgx.Row {
+	return errRow{errors.New("query not supported by stub")}
+}
+
+// errRow is a pgx.Row that fails to scan with the given error.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error {
+	return r.err
+}
+
+// getStatus performs a GET request for the given path against the app, returning the response status code.
+func getStatus(t *testing.T, db DB, migrations fstest.MapFS, path string) int {
+	t.Helper()
+
+	resp, err := New(db, migrations).Test(httptest.NewRequest(http.MethodGet, path, nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	return resp.StatusCode
+}
+
+// TestGetHealthz_ReflectsPing ensures that the liveness probe succeeds when the database can be pinged, and reports 503
+// when it can't.
+func TestGetHealthz_ReflectsPing(t *testing.T) {
+	if status := getStatus(t, stubDB{}, fstest.MapFS{}, "/healthz"); status != http.StatusOK {
+		t.Errorf("Expected status 200 when ping succeeds, got %d", status)
+	}
+
+	db := stubDB{pingErr: errors.New("connection refused")}
+	if status := getStatus(t, db, fstest.MapFS{}, "/healthz"); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 when ping fails, got %d", status)
+	}
+}
+
+// TestGetReadyz_UnavailableWhenChecksFail ensures that the readiness probe reports 503 if the database can't be pinged,
+// or if the state of the migrations can't be read.
+func TestGetReadyz_UnavailableWhenChecksFail(t *testing.T) {
+	db := stubDB{pingErr: errors.New("connection refused")}
+	if status := getStatus(t, db, fstest.MapFS{}, "/readyz"); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 when ping fails, got %d", status)
+	}
+
+	if status := getStatus(t, stubDB{}, fstest.MapFS{}, "/readyz"); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 when migrations can't be read, got %d", status)
+	}
+}
+
+// TestGetReadyz_RequiresMigrationsAndBars ensures that the readiness probe only succeeds once every migration has been
+// applied and at least one bar exists.
+func TestGetReadyz_RequiresMigrationsAndBars(t *testing.T) {
+	pool := databasetest.NewPool(t)
+	ctx := context.Background()
+	migrations := fstest.MapFS{
+		"1_create_bars.sql": &fstest.MapFile{},
+		"2_other.sql":       &fstest.MapFile{},
+	}
+
+	_, err := pool.Exec(ctx, `CREATE TABLE migrations (name VARCHAR(255));
+		INSERT INTO migrations (name) VALUES ('1_create_bars.sql');
+		CREATE TABLE bars (s_id TEXT, ts TIMESTAMPTZ);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status := getStatus(t, pool, migrations, "/readyz"); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 with a pending migration, got %d", status)
+	}
+
+	if _, err := pool.Exec(ctx, "INSERT INTO migrations (name) VALUES ('2_other.sql')"); err != nil {
+		t.Fatal(err)
+	}
+	if status := getStatus(t, pool, migrations, "/readyz"); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 with no bars, got %d", status)
+	}
+
+	if _, err := pool.Exec(ctx, "INSERT INTO bars VALUES ('AAPL', now())"); err != nil {
+		t.Fatal(err)
+	}
+	if status := getStatus(t, pool, migrations, "/readyz"); status != http.StatusOK {
+		t.Errorf("Expected status 200 once migrated with bars, got %d", status)
+	}
+}
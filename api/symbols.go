@@ -0,0 +1,42 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+// latestBar is the timestamp of the most recent bar of a symbol, as returned by the latest symbols endpoint.
+type latestBar struct {
+	Symbol string    `json:"symbol"`
+	Latest time.Time `json:"latest"`
+}
+
+// latestBarsQuery selects the timestamp of the most recent bar of each symbol.
+const latestBarsQuery = "SELECT s_id, MAX(ts) FROM bars GROUP BY s_id ORDER BY s_id"
+
+// getLatestSymbols handles `GET /symbols/latest`, returning the timestamp of the most recent bar of every symbol so that
+// clients can show how fresh the data is. An empty array is returned if there are no bars.
+func (h handlers) getLatestSymbols(c *fiber.Ctx) error {
+	rows, err := h.db.Query(c.UserContext(), latestBarsQuery)
+	if err != nil {
+		return fmt.Errorf("unable to query latest bars: %w", err)
+	}
+
+	latest, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (latestBar, error) {
+		var l latestBar
+		err := row.Scan(&l.Symbol, &l.Latest)
+		return l, err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to read latest bars: %w", err)
+	}
+
+	if latest == nil {
+		latest = []latestBar{}
+	}
+
+	return c.JSON(latest)
+}
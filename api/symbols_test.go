@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"traderkit-server/database/databasetest"
+)
+
+// getLatestSymbols performs a `GET /symbols/latest` request against the app, decoding the successful response.
+func getLatestSymbols(t *testing.T, db DB) []latestBar {
+	t.Helper()
+
+	resp, err := New(db, nil).Test(httptest.NewRequest(http.MethodGet, "/symbols/latest", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", resp.StatusCode, body)
+	}
+
+	var latest []latestBar
+	if err := json.Unmarshal(body, &latest); err != nil {
+		t.Fatal(err)
+	}
+	if latest == nil {
+		t.Fatalf("Expected a JSON array, got %s", body)
+	}
+
+	return latest
+}
+
+// TestGetLatestSymbols_ReturnsLatestPerSymbol ensures that an empty table produces an empty array, and that once seeded
+// the most recent bar timestamp of each symbol is returned.
+func TestGetLatestSymbols_ReturnsLatestPerSymbol(t *testing.T) {
+	pool := databasetest.NewPool(t)
+	ctx := context.Background()
+
+	if _, err := pool.Exec(ctx, "CREATE TABLE bars (s_id TEXT, ts TIMESTAMPTZ)"); err != nil {
+		t.Fatal(err)
+	}
+	if latest := getLatestSymbols(t, pool); len(latest) != 0 {
+		t.Errorf("Expected no symbols for an empty table, got %+v", latest)
+	}
+
+	_, err := pool.Exec(ctx, `INSERT INTO bars VALUES
+		('MSFT', '2025-07-09T19:59:00Z'),
+		('AAPL', '2025-07-10T19:59:00Z'),
+		('AAPL', '2025-07-09T19:59:00Z'),
+		('NVDA', '2025-07-08T14:30:00Z')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []latestBar{
+		{"AAPL", time.Date(2025, 7, 10, 19, 59, 0, 0, time.UTC)},
+		{"MSFT", time.Date(2025, 7, 9, 19, 59, 0, 0, time.UTC)},
+		{"NVDA", time.Date(2025, 7, 8, 14, 30, 0, 0, time.UTC)},
+	}
+	latest := getLatestSymbols(t, pool)
+	if len(latest) != len(expected) {
+		t.Fatalf("Expected %d symbols, got %+v", len(expected), latest)
+	}
+	for i := range expected {
+		if latest[i].Symbol != expected[i].Symbol || !latest[i].Latest.Equal(expected[i].Latest) {
+			t.Errorf("Expected %+v, got %+v", expected[i], latest[i])
+		}
+	}
+}
+
+// TestGetLatestSymbols_FailsWhenQueryFails ensures that a database error is reported as a server error rather than an
+// empty result.
+func TestGetLatestSymbols_FailsWhenQueryFails(t *testing.T) {
+	resp, err := New(stubDB{}, nil).Test(httptest.NewRequest(http.MethodGet, "/symbols/latest", nil))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,69 @@
+// Package config loads the application configuration, which spans the settings of several packages, from the
+// environment.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"traderkit-server/database"
+)
+
+// defaultPort is the port the HTTP server listens on when `PORT` is unset.
+const defaultPort = 3000
+
+// defaultRetentionPeriodDays is the number of market days of bars retained when `RETENTION_PERIOD_DAYS` is unset.
+const defaultRetentionPeriodDays = 14
+
+// Config is the application configuration read from the environment by LoadConfig.
+type Config struct {
+	Database database.Config // The database settings from database.ConfigFromEnv, which require `DATABASE_URL`
+	Port     int             // The port the HTTP server listens on, from `PORT`, defaulting to 3000
+
+	// RetentionPeriodDays is the number of market days of bars to retain, as passed to utils.LastRetainedDay. It is
+	// read from `RETENTION_PERIOD_DAYS`, which must be a whole number from 0 to 255, and defaults to 14 when unset.
+	RetentionPeriodDays uint8
+}
+
+// LoadConfig reads and validates the application configuration from the environment, so that misconfiguration is
+// caught at startup rather than when a value is first used. Rather than stopping at the first problem, every missing or
+// invalid variable is reported in a single joined error.
+func LoadConfig() (Config, error) {
+	var cfg Config
+	var errs []error
+
+	if os.Getenv("DATABASE_URL") == "" {
+		errs = append(errs, errors.New("DATABASE_URL is required"))
+	}
+	db, err := database.ConfigFromEnv()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	cfg.Database = db
+
+	cfg.Port = defaultPort
+	if v := os.Getenv("PORT"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 1 || p > 65535 {
+			errs = append(errs, fmt.Errorf("PORT must be a number between 1 and 65535, got %q", v))
+		}
+		cfg.Port = p
+	}
+
+	cfg.RetentionPeriodDays = defaultRetentionPeriodDays
+	if v := os.Getenv("RETENTION_PERIOD_DAYS"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 8)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("RETENTION_PERIOD_DAYS must be a number between 0 and 255, got %q", v))
+		}
+		cfg.RetentionPeriodDays = uint8(n)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
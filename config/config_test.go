@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"traderkit-server/database"
+)
+
+// TestLoadConfig_ReadsValidConfig ensures that a fully configured environment is read into the Config.
+func TestLoadConfig_ReadsValidConfig(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://db")
+	t.Setenv("PORT", "8080")
+	t.Setenv("RETENTION_PERIOD_DAYS", "30")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	expected := Config{Database: database.Config{URL: "postgres://db"}, Port: 8080, RetentionPeriodDays: 30}
+	if cfg != expected {
+		t.Errorf("Expected %+v but got %+v", expected, cfg)
+	}
+}
+
+// TestLoadConfig_DefaultsOptionalValues ensures that optional variables fall back to their defaults when unset.
+func TestLoadConfig_DefaultsOptionalValues(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://db")
+	unsetEnv(t, "PORT")
+	unsetEnv(t, "RETENTION_PERIOD_DAYS")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if cfg.Port != 3000 {
+		t.Errorf("Expected Port to default to 3000, got %d", cfg.Port)
+	}
+	if cfg.RetentionPeriodDays != 14 {
+		t.Errorf("Expected RetentionPeriodDays to default to 14, got %d", cfg.RetentionPeriodDays)
+	}
+}
+
+// TestLoadConfig_RetentionPeriodDays ensures that an explicit zero retention period is kept rather than replaced by the
+// default, and that values outside of 0 to 255 are rejected with the offending value.
+func TestLoadConfig_RetentionPeriodDays(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://db")
+
+	t.Setenv("RETENTION_PERIOD_DAYS", "0")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if cfg.RetentionPeriodDays != 0 {
+		t.Errorf("Expected RetentionPeriodDays to be 0, got %d", cfg.RetentionPeriodDays)
+	}
+
+	for _, v := range []string{"256", "-1", "two weeks"} {
+		t.Setenv("RETENTION_PERIOD_DAYS", v)
+		_, err := LoadConfig()
+		if err == nil || !strings.Contains(err.Error(), "RETENTION_PERIOD_DAYS") || !strings.Contains(err.Error(), v) {
+			t.Errorf("Expected an error naming RETENTION_PERIOD_DAYS=%s, got %v", v, err)
+		}
+	}
+}
+
+// TestLoadConfig_MissingRequired ensures that a missing required variable is reported by name.
+func TestLoadConfig_MissingRequired(t *testing.T) {
+	unsetEnv(t, "DATABASE_URL")
+	unsetEnv(t, "PORT")
+
+	_, err := LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "DATABASE_URL") {
+		t.Errorf("Expected an error naming DATABASE_URL, got %v", err)
+	}
+}
+
+// TestLoadConfig_ReportsDatabaseSettings ensures that invalid database settings are reported alongside the other
+// problems in the same error, rather than only once the rest of the configuration is valid.
+func TestLoadConfig_ReportsDatabaseSettings(t *testing.T) {
+	unsetEnv(t, "DATABASE_URL")
+	t.Setenv("PORT", "http")
+	t.Setenv("DB_MAX_CONNS", "many")
+	t.Setenv("DB_MAX_CONN_LIFETIME", "30")
+
+	_, err := LoadConfig()
+	if err == nil {
+		t.Fatal("Expected an error, got nil")
+	}
+	for _, want := range []string{"DATABASE_URL", "PORT", "DB_MAX_CONNS", "DB_MAX_CONN_LIFETIME"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got: %v", want, err)
+		}
+	}
+}
+
+// TestLoadConfig_InvalidInteger ensures that an unparseable or out of range integer is rejected with its value, and
+// that it is reported alongside any other problems rather than stopping at the first.
+func TestLoadConfig_InvalidInteger(t *testing.T) {
+	for _, port := range []string{"http", "0", "65536"} {
+		t.Run(port, func(t *testing.T) {
+			unsetEnv(t, "DATABASE_URL")
+			t.Setenv("PORT", port)
+
+			_, err := LoadConfig()
+			if err == nil {
+				t.Fatalf("Expected an error for PORT=%s, got nil", port)
+			}
+			for _, want := range []string{"DATABASE_URL", "PORT", port} {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to contain %q, got: %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+// unsetEnv removes a variable from the environment for the duration of a test, restoring its original value after.
+func unsetEnv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	if err := os.Unsetenv(key); err != nil {
+		t.Fatal(err)
+	}
+}
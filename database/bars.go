@@ -0,0 +1,27 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CreateBarsHypertable converts the `bars` table created by the `001_create_bars` migration into a TimescaleDB
+// hypertable partitioned on `ts`, installing the extension first if needed. Existing rows are moved into chunks, and
+// calling it again once the table is a hypertable does nothing. The composite primary key includes `ts`, as TimescaleDB
+// requires of every unique index on a hypertable.
+func CreateBarsHypertable(pool *pgxpool.Pool) error {
+	_, err := pool.Exec(context.Background(), "CREATE EXTENSION IF NOT EXISTS timescaledb")
+	if err != nil {
+		return fmt.Errorf("unable to create timescaledb extension: %w", err)
+	}
+
+	_, err = pool.Exec(context.Background(),
+		"SELECT create_hypertable('bars', 'ts', if_not_exists => TRUE, migrate_data => TRUE)")
+	if err != nil {
+		return fmt.Errorf("unable to convert bars to a hypertable: %w", err)
+	}
+
+	return nil
+}
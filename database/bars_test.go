@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"traderkit-server/database/databasetest"
+)
+
+// TestMigrations_BarsUpsertTarget ensures that the embedded migrations create a `bars` table whose composite primary key
+// can be used as the target of an upsert, so that re-ingesting a bar replaces it rather than duplicating it.
+func TestMigrations_BarsUpsertTarget(t *testing.T) {
+	pool := databasetest.NewPool(t)
+	ctx := context.Background()
+
+	if err := runMigrations(pool, Migrations); err != nil {
+		t.Fatalf("runMigrations returned error: %v", err)
+	}
+
+	upsert := `INSERT INTO bars (s_id, ts, o, h, l, c, v, txns)
+		VALUES ('AAPL', '2025-07-10T14:00:00Z', 10, 12, 9, $1, 100, 5)
+		ON CONFLICT (s_id, ts) DO UPDATE SET c = excluded.c`
+	for _, c := range []float64{11, 11.25} {
+		if _, err := pool.Exec(ctx, upsert, c); err != nil {
+			t.Fatalf("Upsert returned error: %v", err)
+		}
+	}
+
+	var count int
+	var c float64
+	if err := pool.QueryRow(ctx, "SELECT count(*), max(c)::float8 FROM bars").Scan(&count, &c); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || c != 11.25 {
+		t.Errorf("Expected a single bar closing at 11.25, got %d bars closing at %v", count, c)
+	}
+}
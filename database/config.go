@@ -0,0 +1,101 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Config configures the connection pool created by NewWithConfig. Zero values leave the pgxpool defaults in place.
+type Config struct {
+	URL             string        // The Postgres connection string
+	MaxConns        int32         // The maximum number of connections in the pool
+	MinConns        int32         // The minimum number of idle connections kept open
+	MaxConnLifetime time.Duration // How long a connection may live before it is closed and replaced
+	MaxConnIdleTime time.Duration // How long a connection may sit idle before it is closed
+	Migrations      fs.FS         // The migrations to apply, defaulting to the embedded Migrations
+	Hypertable      bool          // Whether to convert `bars` to a TimescaleDB hypertable once migrations are applied
+}
+
+// ConfigFromEnv builds a Config from the `DATABASE_URL`, `DB_MAX_CONNS`, `DB_MIN_CONNS`, `DB_MAX_CONN_LIFETIME`,
+// `DB_MAX_CONN_IDLE_TIME` and `DB_BARS_HYPERTABLE` environment variables. Durations use time.ParseDuration syntax, such
+// as `30m`, and booleans use strconv.ParseBool syntax. Unset variables are left as zero values. Every invalid variable
+// is reported in a single joined error, rather than stopping at the first.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{URL: os.Getenv("DATABASE_URL")}
+	var errs []error
+
+	for _, v := range []struct {
+		name string
+		dst  *int32
+	}{{"DB_MAX_CONNS", &cfg.MaxConns}, {"DB_MIN_CONNS", &cfg.MinConns}} {
+		if s := os.Getenv(v.name); s != "" {
+			n, err := strconv.ParseInt(s, 10, 32)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s %q: %w", v.name, s, err))
+			}
+			*v.dst = int32(n)
+		}
+	}
+
+	for _, v := range []struct {
+		name string
+		dst  *time.Duration
+	}{{"DB_MAX_CONN_LIFETIME", &cfg.MaxConnLifetime}, {"DB_MAX_CONN_IDLE_TIME", &cfg.MaxConnIdleTime}} {
+		if s := os.Getenv(v.name); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s %q: %w", v.name, s, err))
+			}
+			*v.dst = d
+		}
+	}
+
+	if s := os.Getenv("DB_BARS_HYPERTABLE"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid DB_BARS_HYPERTABLE %q: %w", s, err))
+		}
+		cfg.Hypertable = b
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// poolConfig parses the connection string and applies the pool sizing and lifetimes from the Config to it.
+func (c Config) poolConfig() (*pgxpool.Config, error) {
+	pc, err := pgxpool.ParseConfig(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse database URL: %w", err)
+	}
+
+	if c.MaxConns < 0 {
+		return nil, fmt.Errorf("max connections must be at least 1, got %d", c.MaxConns)
+	}
+	if c.MaxConns > 0 {
+		pc.MaxConns = c.MaxConns
+	}
+	if c.MinConns < 0 || c.MinConns > pc.MaxConns {
+		return nil, fmt.Errorf("min connections must be between 0 and %d, got %d", pc.MaxConns, c.MinConns)
+	}
+	if c.MinConns > 0 {
+		pc.MinConns = c.MinConns
+	}
+	if c.MaxConnLifetime > 0 {
+		pc.MaxConnLifetime = c.MaxConnLifetime
+	}
+	if c.MaxConnIdleTime > 0 {
+		pc.MaxConnIdleTime = c.MaxConnIdleTime
+	}
+
+	return pc, nil
+}
@@ -1,109 +1,426 @@
 package database
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"maps"
 	"slices"
-	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/jackc/pgx/v5"
 )
 
-// New creates a new database connection, initializes the `migrations` table if it doesn't exist,
-// and then runs any migrations that haven't already been applied.
-func New() *pgxpool.Pool {
-	dbUrl := os.Getenv("DATABASE_URL")
-	pool, err := pgxpool.New(context.Background(), dbUrl)
+//go:embed migrations
+var embeddedMigrations embed.FS
+
+// Migrations is the set of migration files compiled into the binary from the `migrations` directory, so that the
+// deployed binary is self-contained and doesn't depend on the working directory it is run from.
+var Migrations, _ = fs.Sub(embeddedMigrations, "migrations")
+
+// migrationLockKey is the key of the Postgres advisory lock held while migrations are applied or rolled back, so that
+// only one server instance modifies the schema at a time. The value is arbitrary, but must be stable across releases.
+const migrationLockKey int64 = 0x7472_6164_6b69_74 // "tradkit"
+
+// New creates a new database connection using the Config from ConfigFromEnv, initializes the `migrations` table if it
+// doesn't exist, and then runs any migrations from the root of `migrations` that haven't already been applied. If
+// `migrations` is nil, the embedded Migrations are used.
+func New(migrations fs.FS) (*pgxpool.Pool, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Migrations = migrations
+
+	return NewWithConfig(cfg)
+}
+
+// NewWithConfig creates a new database connection pool tuned by the given Config, initializes the `migrations` table if
+// it doesn't exist, and then runs any migrations that haven't already been applied. If the Config enables Hypertable,
+// the `bars` table is then converted with CreateBarsHypertable.
+func NewWithConfig(cfg Config) (*pgxpool.Pool, error) {
+	migrations := cfg.Migrations
+	if migrations == nil {
+		migrations = Migrations
+	}
+
+	pc, err := cfg.poolConfig()
 	if err != nil {
-		fmt.Printf("Unable to connect to database: %v\n", err)
-		os.Exit(1)
+		return nil, err
 	}
 
-	_, err = pool.Exec(context.Background(), "CREATE TABLE IF NOT EXISTS migrations (name VARCHAR(255))")
+	pool, err := pgxpool.NewWithConfig(context.Background(), pc)
 	if err != nil {
-		fmt.Printf("Unable to create migrations table: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("unable to connect to database: %w", err)
+	}
+
+	if err := runMigrations(pool, migrations); err != nil {
+		pool.Close()
+		return nil, err
 	}
 
-	runMigrations(pool)
+	if cfg.Hypertable {
+		if err := CreateBarsHypertable(pool); err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
 
-	return pool
+	return pool, nil
 }
 
-// runMigrations gathers the `.sql` files in the migration directory, retrieves the applied migrations from the
-// database, and then compares
-func runMigrations(pool *pgxpool.Pool) {
-	allMigrations, err := filepath.Glob("./migrations/*.sql")
+// withMigrationLock runs `fn` while holding the migration advisory lock, blocking until any other instance that holds
+// the lock releases it. The lock is tied to a single connection's session, so that connection is held for the duration
+// and passed to `fn`. All of `fn`'s work should run on it, so that a pool of a single connection doesn't deadlock.
+func withMigrationLock(pool *pgxpool.Pool, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := pool.Acquire(context.Background())
 	if err != nil {
-		fmt.Printf("Unable to read migrations directory: %v\n", err)
+		return fmt.Errorf("unable to acquire connection for migration lock: %w", err)
 	}
+	defer conn.Release()
 
-	rows, err := pool.Query(context.Background(), "SELECT * FROM migrations")
+	_, err = conn.Exec(context.Background(), "SELECT pg_advisory_lock($1)", migrationLockKey)
 	if err != nil {
-		fmt.Printf("Unable to read migrations from table: %v\n", err)
+		return fmt.Errorf("unable to acquire migration lock: %w", err)
 	}
+	defer func() {
+		_, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+		if err != nil {
+			// Closing the connection ends its session, which releases the lock rather than returning it to the pool.
+			_ = conn.Conn().Close(context.Background())
+		}
+	}()
 
-	appliedMigrations, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	return fn(conn)
+}
+
+// runMigrations creates the `migrations` table if needed, gathers the `.sql` files in the migrations filesystem (other
+// than `.down.sql` files, which are only used by Rollback), retrieves the applied migrations from the database, and
+// then applies any files that have not been applied yet. Before applying anything, the files of previously applied
+// migrations are checked against their recorded checksums so that edits to them are not silently ignored. The
+// migration lock is held throughout, so concurrent callers apply each migration exactly once.
+func runMigrations(pool *pgxpool.Pool, migrations fs.FS) error {
+	return withMigrationLock(pool, func(conn *pgxpool.Conn) error { return applyMigrations(conn, migrations) })
+}
+
+// applyMigrations performs the work of runMigrations on the connection holding the migration lock.
+func applyMigrations(conn *pgxpool.Conn, migrations fs.FS) error {
+	_, err := conn.Exec(context.Background(), "CREATE TABLE IF NOT EXISTS migrations (name VARCHAR(255))")
+	if err != nil {
+		return fmt.Errorf("unable to create migrations table: %w", err)
+	}
+
+	// Migrations applied before checksums were recorded are left with a `NULL` checksum and can't be checked for drift.
+	_, err = conn.Exec(context.Background(), "ALTER TABLE migrations ADD COLUMN IF NOT EXISTS checksum CHAR(64)")
 	if err != nil {
-		fmt.Printf("CollectRows for applied migrations error: %v\n", err)
+		return fmt.Errorf("unable to add checksum column to migrations table: %w", err)
 	}
 
-	sort.Strings(allMigrations)
-	sort.Strings(appliedMigrations)
+	// The default is set separately so that migrations applied before timestamps were recorded are left with a `NULL`
+	// time, rather than being given the time the column was added.
+	_, err = conn.Exec(context.Background(), `ALTER TABLE migrations ADD COLUMN IF NOT EXISTS applied_at TIMESTAMPTZ;
+		ALTER TABLE migrations ALTER COLUMN applied_at SET DEFAULT now();`)
+	if err != nil {
+		return fmt.Errorf("unable to add applied_at column to migrations table: %w", err)
+	}
+
+	allMigrations, err := migrationFiles(migrations)
+	if err != nil {
+		return err
+	}
+
+	appliedMigrations, err := appliedMigrations(conn)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyChecksums(conn, migrations, allMigrations); err != nil {
+		return fmt.Errorf("unable to verify applied migrations: %w", err)
+	}
 
 	unappliedMigrations := migrationDifference(allMigrations, appliedMigrations)
 
 	for _, file := range unappliedMigrations {
-		executeMigrationFile(pool, file)
+		if err := executeMigrationFile(conn, migrations, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Querier is the subset of *pgxpool.Pool needed to read the state of the database, which allows it to be stubbed.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// PendingMigrations returns the names of the migrations in `migrations` that have not been applied to the database, in
+// the order they would be applied.
+func PendingMigrations(q Querier, migrations fs.FS) ([]string, error) {
+	all, err := migrationFiles(migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrationDifference(all, applied), nil
+}
+
+// Migration describes a migration file and whether it has been applied to the database.
+type Migration struct {
+	Name      string     // The file name of the migration, as recorded in the `migrations` table
+	Applied   bool       // Whether the migration has been applied
+	AppliedAt *time.Time // When the migration was applied, or nil if pending or applied before this was recorded
+}
+
+// MigrationStatus returns every migration in `migrations`, in the order they are applied, along with whether and when
+// each has been applied to the database.
+func MigrationStatus(q Querier, migrations fs.FS) ([]Migration, error) {
+	all, err := migrationFiles(migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(q)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]Migration, len(all))
+	for i, name := range all {
+		appliedAt, ok := applied[name]
+		status[i] = Migration{Name: name, Applied: ok, AppliedAt: appliedAt}
+	}
+
+	return status, nil
+}
+
+// migrationFiles returns the names of the forward migrations at the root of `migrations`, sorted in the order they
+// should be applied. `.down.sql` files are excluded as they are only applied by Rollback.
+func migrationFiles(migrations fs.FS) ([]string, error) {
+	files, err := fs.Glob(migrations, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations: %w", err)
+	}
+	files = slices.DeleteFunc(files, isDownMigration)
+
+	if err := sortMigrations(files); err != nil {
+		return nil, err
 	}
+
+	return files, nil
 }
 
-// executeMigrationFile reads the contents of a migration file and applies to against the database using the provided
-// connection. It also inserts a record of the migration into the `migrations` table to track that the migration has
-// been applied.
-func executeMigrationFile(pool *pgxpool.Pool, fileName string) {
-	contents, err := os.ReadFile(fileName)
+// appliedMigrations returns the names of all migrations recorded in the `migrations` table, mapped to the time each was
+// applied, which is nil for migrations applied before this was recorded.
+func appliedMigrations(q Querier) (map[string]*time.Time, error) {
+	rows, err := q.Query(context.Background(), "SELECT name, applied_at FROM migrations")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations from table: %w", err)
+	}
+
+	applied := make(map[string]*time.Time)
+	var name string
+	var appliedAt *time.Time
+	_, err = pgx.ForEachRow(rows, []any{&name, &appliedAt}, func() error {
+		applied[name] = appliedAt
+		return nil
+	})
 	if err != nil {
-		fmt.Printf("Unable to read unapplied migration file %s: %v\n", fileName, err)
-		os.Exit(1)
+		return nil, fmt.Errorf("unable to collect applied migrations: %w", err)
 	}
 
-	// Initiate a transaction, rolling back after the method completes.
-	tx, err := pool.Begin(context.Background())
+	return applied, nil
+}
+
+// verifyChecksums compares the checksum of each of the given migration files against the checksum recorded when it was
+// applied, returning an error naming the first file that has changed. Files that haven't been applied, or that were
+// applied before checksums were recorded, are skipped.
+func verifyChecksums(q Querier, migrations fs.FS, files []string) error {
+	rows, err := q.Query(context.Background(), "SELECT name, checksum FROM migrations WHERE checksum IS NOT NULL")
 	if err != nil {
-		fmt.Printf("Unable to begin transaction for migration %s: %v\n", fileName, err)
-		os.Exit(1)
+		return fmt.Errorf("unable to read migration checksums: %w", err)
 	}
-	defer tx.Rollback(context.Background())
 
-	// Apply the migration
-	_, err = tx.Exec(context.Background(), string(contents))
+	recorded := make(map[string]string)
+	var name, checksum string
+	_, err = pgx.ForEachRow(rows, []any{&name, &checksum}, func() error {
+		recorded[name] = checksum
+		return nil
+	})
 	if err != nil {
-		fmt.Printf("Unable to apply migration %s: %v\n", fileName, err)
-		os.Exit(1)
+		return fmt.Errorf("unable to read migration checksums: %w", err)
+	}
+
+	for _, file := range files {
+		checksum, ok := recorded[file]
+		if !ok {
+			continue
+		}
+
+		contents, err := fs.ReadFile(migrations, file)
+		if err != nil {
+			return fmt.Errorf("unable to read migration file %s: %w", file, err)
+		}
+		if migrationChecksum(contents) != checksum {
+			return fmt.Errorf("migration %s has been modified since it was applied", file)
+		}
 	}
 
-	_, err = tx.Exec(context.Background(), "INSERT INTO migrations (name) VALUES ($1);", fileName)
+	return nil
+}
+
+// migrationChecksum returns the hex-encoded SHA-256 checksum of a migration file's contents.
+func migrationChecksum(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// executeMigrationFile reads the contents of a migration file and applies it against the database within a transaction
+// from WithTx on the given connection. It also inserts a record of the migration and its checksum into the `migrations`
+// table in the same transaction to track that the migration has been applied, with the time it was applied left to the
+// column's default.
+func executeMigrationFile(db TxBeginner, migrations fs.FS, fileName string) error {
+	contents, err := fs.ReadFile(migrations, fileName)
+	if err != nil {
+		return fmt.Errorf("unable to read unapplied migration file %s: %w", fileName, err)
+	}
+
+	err = WithTx(context.Background(), db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(context.Background(), string(contents)); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(context.Background(), "INSERT INTO migrations (name, checksum) VALUES ($1, $2);", fileName,
+			migrationChecksum(contents))
+		if err != nil {
+			return fmt.Errorf("unable to persist migration status: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to apply migration %s: %w", fileName, err)
+	}
+	fmt.Printf("Applied migration %s successfully.\n", fileName)
+
+	return nil
+}
+
+// Rollback reverts the last `n` applied migrations, most recent first. Only migrations named `NNN_name.up.sql` can be
+// rolled back, by running the `NNN_name.down.sql` file beside it in `migrations`. Each down migration is applied in its
+// own transaction together with the removal of its record from the `migrations` table. As with runMigrations, the
+// migration lock is held throughout.
+func Rollback(pool *pgxpool.Pool, migrations fs.FS, n int) error {
+	return withMigrationLock(pool, func(conn *pgxpool.Conn) error { return rollbackMigrations(conn, migrations, n) })
+}
+
+// rollbackMigrations reverts the last `n` applied migrations on the connection holding the migration lock.
+func rollbackMigrations(conn *pgxpool.Conn, migrations fs.FS, n int) error {
+	appliedAt, err := appliedMigrations(conn)
 	if err != nil {
-		fmt.Printf("Unable to persist migration status %s: %v\n", fileName, err)
-		os.Exit(1)
+		return err
 	}
+	applied := slices.Collect(maps.Keys(appliedAt))
+	if err := sortMigrations(applied); err != nil {
+		return err
+	}
+
+	n = max(0, min(n, len(applied)))
+	for _, name := range slices.Backward(applied[len(applied)-n:]) {
+		if err := executeDownMigrationFile(conn, migrations, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// executeDownMigrationFile reads the down migration paired with the given applied migration and applies it against the
+// database, deleting the migration's record from the `migrations` table.
+func executeDownMigrationFile(db TxBeginner, migrations fs.FS, fileName string) error {
+	if !strings.HasSuffix(fileName, ".up.sql") {
+		return fmt.Errorf("unable to roll back migration %s as it is not an .up.sql migration", fileName)
+	}
+
+	downFileName := strings.TrimSuffix(fileName, ".up.sql") + ".down.sql"
+	contents, err := fs.ReadFile(migrations, downFileName)
+	if err != nil {
+		return fmt.Errorf("unable to read down migration file %s: %w", downFileName, err)
+	}
+
+	err = WithTx(context.Background(), db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(context.Background(), string(contents)); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(context.Background(), "DELETE FROM migrations WHERE name = $1;", fileName); err != nil {
+			return fmt.Errorf("unable to remove migration status: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to apply down migration %s: %w", downFileName, err)
+	}
+	fmt.Printf("Rolled back migration %s successfully.\n", fileName)
+
+	return nil
+}
+
+// migrationNumber parses the numeric prefix of a migration file name, such as `12` from `012_create_bars.up.sql`.
+func migrationNumber(fileName string) (int, error) {
+	prefix, _, ok := strings.Cut(fileName, "_")
+	if !ok || prefix == "" || strings.Trim(prefix, "0123456789") != "" {
+		return 0, fmt.Errorf("migration %s does not have a numeric NNN_ prefix", fileName)
+	}
+
+	return strconv.Atoi(prefix)
+}
+
+// sortMigrations sorts migration file names in the order they should be applied, by their numeric prefix rather than
+// lexicographically, so that `10_foo.sql` comes after `2_foo.sql`. Files with the same number are ordered by name. An
+// error is returned if any file lacks a numeric prefix.
+func sortMigrations(files []string) error {
+	numbers := make(map[string]int, len(files))
+	for _, f := range files {
+		n, err := migrationNumber(f)
+		if err != nil {
+			return err
+		}
+		numbers[f] = n
+	}
+
+	slices.SortFunc(files, func(a, b string) int {
+		return cmp.Or(cmp.Compare(numbers[a], numbers[b]), strings.Compare(a, b))
+	})
+
+	return nil
+}
 
-	err = tx.Commit(context.Background())
-	fmt.Printf("Appled migration %s successfully.\n", fileName)
+// isDownMigration reports whether the given file is a `.down.sql` migration, which is only applied by Rollback.
+func isDownMigration(fileName string) bool {
+	return strings.HasSuffix(fileName, ".down.sql")
 }
 
 // migrationDifference returns a slice of migration file names that are in `all` but not in `applied`—these are the
-// unapplied migrations that need to be executed for the application to boot. This function is currently O(n^2) and
-// could be sped up in future by using a `map` for O(1) lookups (O(n) overall).
-func migrationDifference(all, applied []string) []string {
+// unapplied migrations that need to be executed for the application to boot. The order of `all` is preserved.
+func migrationDifference(all []string, applied map[string]*time.Time) []string {
 	unapplied := make([]string, 0)
 	for _, m := range all {
-		if !slices.Contains(applied, m) {
+		if _, ok := applied[m]; !ok {
 			unapplied = append(unapplied, m)
 		}
 	}
@@ -0,0 +1,60 @@
+// Package databasetest provides helpers for tests that run against a real Postgres database.
+package databasetest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NewPool connects to the database at `TEST_DATABASE_URL`, skipping the test if it is not set. Each test runs in its
+// own freshly created schema, which is dropped once the test completes.
+func NewPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dbUrl := os.Getenv("TEST_DATABASE_URL")
+	if dbUrl == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	schema := pgx.Identifier{fmt.Sprintf("test_%d", time.Now().UnixNano())}.Sanitize()
+
+	conn, err := pgx.Connect(ctx, dbUrl)
+	if err != nil {
+		t.Fatalf("Unable to connect to test database: %v", err)
+	}
+	defer conn.Close(ctx)
+	if _, err := conn.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("Unable to create test schema: %v", err)
+	}
+
+	cfg, err := pgxpool.ParseConfig(dbUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Unable to connect to test database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+		conn, err := pgx.Connect(ctx, dbUrl)
+		if err != nil {
+			t.Errorf("Unable to drop test schema: %v", err)
+			return
+		}
+		defer conn.Close(ctx)
+		_, _ = conn.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE")
+	})
+
+	return pool
+}
@@ -0,0 +1,13 @@
+-- Each row is a one-minute OHLCV bar of a symbol, keyed by the symbol and the start of the minute it covers. Prices are
+-- stored as exact decimals so that aggregations don't accumulate floating point error.
+CREATE TABLE bars (
+    s_id TEXT NOT NULL,
+    ts TIMESTAMPTZ NOT NULL,
+    o NUMERIC(19, 6) NOT NULL,
+    h NUMERIC(19, 6) NOT NULL,
+    l NUMERIC(19, 6) NOT NULL,
+    c NUMERIC(19, 6) NOT NULL,
+    v NUMERIC NOT NULL,
+    txns BIGINT NOT NULL,
+    PRIMARY KEY (s_id, ts)
+);
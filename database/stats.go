@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReportPoolStats logs a snapshot of the pool's connection statistics every `interval` until `ctx` is canceled, so that
+// pool saturation can be diagnosed from the logs. A rising `empty_acquire_count` or `acquire_duration` indicates that
+// callers are waiting for connections. It blocks, so is typically run in its own goroutine.
+func ReportPoolStats(ctx context.Context, pool *pgxpool.Pool, logger *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s := pool.Stat()
+			logger.LogAttrs(ctx, slog.LevelInfo, "database pool stats",
+				slog.Int("acquired_conns", int(s.AcquiredConns())),
+				slog.Int("idle_conns", int(s.IdleConns())),
+				slog.Int("total_conns", int(s.TotalConns())),
+				slog.Int("max_conns", int(s.MaxConns())),
+				slog.Int64("acquire_count", s.AcquireCount()),
+				slog.Duration("acquire_duration", s.AcquireDuration()),
+				slog.Int64("empty_acquire_count", s.EmptyAcquireCount()),
+				slog.Int64("canceled_acquire_count", s.CanceledAcquireCount()),
+			)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TxBeginner is implemented by both *pgxpool.Pool and *pgxpool.Conn, so that a transaction can be run on a connection
+// that is already held, such as the one holding the migration lock.
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WithTx runs `fn` within a transaction on `db`, committing it if `fn` returns nil and rolling it back otherwise.
+// The transaction is also rolled back if `fn` panics, before the panic continues. Errors returned by `fn` are returned
+// unchanged, so that callers can wrap them with their own context.
+func WithTx(ctx context.Context, db TxBeginner, fn func(pgx.Tx) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	// Rolling back a committed transaction does nothing, so this only takes effect on an error or panic.
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"traderkit-server/database/databasetest"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// insertInTx returns a function for WithTx that inserts a row into table `a` before returning `result`.
+func insertInTx(result error) func(pgx.Tx) error {
+	return func(tx pgx.Tx) error {
+		if _, err := tx.Exec(context.Background(), "INSERT INTO a VALUES (1)"); err != nil {
+			return err
+		}
+		return result
+	}
+}
+
+// countRows returns the number of rows in table `a`.
+func countRows(t *testing.T, pool *pgxpool.Pool) int {
+	t.Helper()
+
+	var n int
+	if err := pool.QueryRow(context.Background(), "SELECT count(*) FROM a").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	return n
+}
+
+// TestWithTx_CommitsOrRollsBack ensures that the transaction is committed when the function succeeds, and rolled back
+// with the function's error returned unchanged when it fails.
+func TestWithTx_CommitsOrRollsBack(t *testing.T) {
+	pool := databasetest.NewPool(t)
+	if _, err := pool.Exec(context.Background(), "CREATE TABLE a (id INT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("failure")
+	if err := WithTx(context.Background(), pool, insertInTx(failure)); !errors.Is(err, failure) {
+		t.Errorf("Expected the function's error to be returned, got %v", err)
+	}
+	if n := countRows(t, pool); n != 0 {
+		t.Errorf("Expected the insert to be rolled back, got %d rows", n)
+	}
+
+	if err := WithTx(context.Background(), pool, insertInTx(nil)); err != nil {
+		t.Errorf("WithTx returned error: %v", err)
+	}
+	if n := countRows(t, pool); n != 1 {
+		t.Errorf("Expected the insert to be committed, got %d rows", n)
+	}
+}
+
+// TestWithTx_RollsBackOnPanic ensures that a panicking function has its transaction rolled back, and that the panic
+// still reaches the caller.
+func TestWithTx_RollsBackOnPanic(t *testing.T) {
+	pool := databasetest.NewPool(t)
+	if _, err := pool.Exec(context.Background(), "CREATE TABLE a (id INT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected the panic to propagate, got %v", r)
+			}
+		}()
+
+		_ = WithTx(context.Background(), pool, func(tx pgx.Tx) error {
+			_ = insertInTx(nil)(tx)
+			panic("boom")
+		})
+	}()
+
+	if n := countRows(t, pool); n != 0 {
+		t.Errorf("Expected the insert to be rolled back, got %d rows", n)
+	}
+}
@@ -1,23 +1,60 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"traderkit-server/api"
+	"traderkit-server/config"
+	"traderkit-server/database"
 	"traderkit-server/utils"
-
-	"github.com/gofiber/fiber/v2"
 )
 
+// poolStatsInterval is how often the database pool's connection statistics are logged.
+const poolStatsInterval = time.Minute
+
 func main() {
-	if err := utils.LoadEnvFile(); err != nil {
+	if err := utils.LoadEnvFile(); err != nil && !errors.Is(err, utils.ErrNoEnvFile) {
+		fmt.Printf("Unable to load .env file: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Printf("Unable to load configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+
+	pool, err := database.NewWithConfig(cfg.Database)
+	if err != nil {
+		fmt.Printf("Unable to initialise database: %v\n", err)
 		os.Exit(1)
 	}
-	app := fiber.New()
+	defer pool.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	app.Get("/", func(c *fiber.Ctx) error {
-		return c.SendString("Hello, World!")
-	})
+	go database.ReportPoolStats(ctx, pool, slog.Default(), poolStatsInterval)
 
-	log.Fatal(app.Listen(":3000"))
+	app := api.New(pool, database.Migrations)
+
+	// Stop accepting requests on SIGINT or SIGTERM, so that in-flight requests finish before the pool is closed.
+	go func() {
+		<-ctx.Done()
+		_ = app.Shutdown()
+	}()
+
+	if err := app.Listen(":" + strconv.Itoa(cfg.Port)); err != nil {
+		pool.Close()
+		log.Fatal(err)
+	}
 }
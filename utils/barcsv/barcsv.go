@@ -0,0 +1,209 @@
+package barcsv
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Header is the header row of a Polygon minute aggregates flat file, in the order its columns are written. The
+// `window_start` column holds the start of the minute as nanoseconds since the Unix epoch.
+var Header = []string{"ticker", "volume", "open", "close", "high", "low", "window_start", "transactions"}
+
+// Columns are the columns of the `bars` table, in the order of the values in each bar tuple. They can be passed to
+// `CopyFrom` along with a Source.
+var Columns = []string{"s_id", "ts", "o", "h", "l", "c", "v", "txns"}
+
+// Write writes bar tuples to `w` as a gzipped CSV in Polygon's flat-file format, with a Header row first. Each tuple is
+// ordered as in Columns and must hold a `string` symbol, a `time.Time` timestamp, `float64` open, high, low, close and
+// volume, and an `int64` number of transactions.
+func Write(w io.Writer, bars [][]any) error {
+	gw := gzip.NewWriter(w)
+	cw := csv.NewWriter(gw)
+
+	if err := cw.Write(Header); err != nil {
+		return fmt.Errorf("unable to write header: %w", err)
+	}
+	for i, bar := range bars {
+		record, err := toRecord(bar)
+		if err != nil {
+			return fmt.Errorf("bar %d: %w", i, err)
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("unable to write bar %d: %w", i, err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("unable to write bars: %w", err)
+	}
+
+	return gw.Close()
+}
+
+// WriteFile writes bar tuples to a gzipped CSV file at the given path, replacing it if it exists. See Write.
+func WriteFile(path string, bars [][]any) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", path, err)
+	}
+	defer func() { err = errors.Join(err, f.Close()) }()
+
+	return Write(f, bars)
+}
+
+// toRecord converts a bar tuple into a CSV record in the order of Header.
+func toRecord(bar []any) ([]string, error) {
+	if len(bar) != len(Columns) {
+		return nil, fmt.Errorf("expected %d values, got %d", len(Columns), len(bar))
+	}
+
+	ticker, ok := bar[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected s_id to be a string, got %T", bar[0])
+	}
+	ts, ok := bar[1].(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("expected ts to be a time.Time, got %T", bar[1])
+	}
+	prices := make([]string, 5)
+	for i := range prices {
+		f, ok := bar[i+2].(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected %s to be a float64, got %T", Columns[i+2], bar[i+2])
+		}
+		prices[i] = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	txns, ok := bar[7].(int64)
+	if !ok {
+		return nil, fmt.Errorf("expected txns to be an int64, got %T", bar[7])
+	}
+
+	o, h, l, c, v := prices[0], prices[1], prices[2], prices[3], prices[4]
+	return []string{ticker, v, o, c, h, l, strconv.FormatInt(ts.UnixNano(), 10), strconv.FormatInt(txns, 10)}, nil
+}
+
+// Source reads bar tuples from a gzipped CSV in Polygon's flat-file format, as written by Write. It implements
+// pgx.CopyFromSource, so it can be passed directly to `CopyFrom` along with Columns. Timestamps are returned in UTC.
+type Source struct {
+	gr     *gzip.Reader
+	cr     *csv.Reader
+	closer io.Closer
+	values []any
+	err    error
+}
+
+var _ pgx.CopyFromSource = (*Source)(nil)
+
+// NewSource creates a Source reading from `r`, returning an error if it isn't gzipped or its header doesn't match
+// Header.
+func NewSource(r io.Reader) (*Source, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress bars: %w", err)
+	}
+
+	cr := csv.NewReader(gr)
+	cr.FieldsPerRecord = len(Header)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read header: %w", err)
+	}
+	if !slices.Equal(header, Header) {
+		return nil, fmt.Errorf("unexpected header %v", header)
+	}
+
+	return &Source{gr: gr, cr: cr}, nil
+}
+
+// Open creates a Source reading from the gzipped CSV file at the given path. The file is closed by Close.
+func Open(path string) (*Source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %w", path, err)
+	}
+
+	s, err := NewSource(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	s.closer = f
+
+	return s, nil
+}
+
+// Next reads the next bar, returning false once every bar has been read or an error has occurred.
+func (s *Source) Next() bool {
+	if s.err != nil {
+		return false
+	}
+
+	record, err := s.cr.Read()
+	if errors.Is(err, io.EOF) {
+		return false
+	}
+	if err != nil {
+		s.err = fmt.Errorf("unable to read bar: %w", err)
+		return false
+	}
+
+	s.values, s.err = fromRecord(record)
+	return s.err == nil
+}
+
+// Values returns the bar tuple read by the last call to Next, ordered as in Columns.
+func (s *Source) Values() ([]any, error) {
+	return s.values, s.err
+}
+
+// Err returns the error, if any, that stopped Next.
+func (s *Source) Err() error {
+	return s.err
+}
+
+// Close releases the decompressor, and closes the file if the Source was created by Open.
+func (s *Source) Close() error {
+	err := s.gr.Close()
+	if s.closer != nil {
+		err = errors.Join(err, s.closer.Close())
+	}
+
+	return err
+}
+
+// fromRecord converts a CSV record in the order of Header into a bar tuple ordered as in Columns.
+func fromRecord(record []string) ([]any, error) {
+	// The volume, open, close, high and low columns, in the order they appear in the record.
+	floats := make([]float64, 5)
+	for i := range floats {
+		f, err := strconv.ParseFloat(record[i+1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", Header[i+1], record[i+1], err)
+		}
+		floats[i] = f
+	}
+	v, o, c, h, l := floats[0], floats[1], floats[2], floats[3], floats[4]
+
+	ns, err := strconv.ParseInt(record[6], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window_start %q: %w", record[6], err)
+	}
+	txns, err := strconv.ParseInt(record[7], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transactions %q: %w", record[7], err)
+	}
+
+	return []any{record[0], time.Unix(0, ns).UTC(), o, h, l, c, v, txns}, nil
+}
@@ -0,0 +1,132 @@
+package barcsv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bars are the tuples written by each round-trip test, with timestamps carrying sub-second precision to ensure that
+// nothing is truncated.
+var bars = [][]any{
+	{"AAPL", time.Date(2025, 7, 10, 13, 30, 0, 0, time.UTC), 210.5, 211.25, 210.1, 211.0, 12345.0, int64(321)},
+	{"BRK.B", time.Date(2025, 7, 10, 13, 31, 0, 123456789, time.UTC), 475.125, 475.5, 474.99, 475.2, 0.5, int64(1)},
+}
+
+// readAll reads every bar tuple from the Source, failing the test if it stops with an error.
+func readAll(t *testing.T, s *Source) [][]any {
+	t.Helper()
+
+	var read [][]any
+	for s.Next() {
+		values, err := s.Values()
+		if err != nil {
+			t.Fatalf("Values returned error: %v", err)
+		}
+		read = append(read, values)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("Source stopped with error: %v", err)
+	}
+
+	return read
+}
+
+// TestWrite_RoundTrips ensures that bars written to a gzipped CSV are read back identically, including timestamps to the
+// nanosecond.
+func TestWrite_RoundTrips(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, bars); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	s, err := NewSource(&buf)
+	if err != nil {
+		t.Fatalf("NewSource returned error: %v", err)
+	}
+	defer s.Close()
+
+	if read := readAll(t, s); !reflect.DeepEqual(read, bars) {
+		t.Errorf("Expected %v but got %v", bars, read)
+	}
+}
+
+// TestWriteFile_RoundTrips ensures that bars written to a file can be read back with Open.
+func TestWriteFile_RoundTrips(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "2025-07-10.csv.gz")
+	if err := WriteFile(path, bars); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	defer s.Close()
+
+	if read := readAll(t, s); !reflect.DeepEqual(read, bars) {
+		t.Errorf("Expected %v but got %v", bars, read)
+	}
+}
+
+// TestWrite_UsesPolygonColumnOrder ensures that the written file matches the layout of a Polygon minute aggregates flat
+// file, so that fixtures are interchangeable with real files.
+func TestWrite_UsesPolygonColumnOrder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, bars[:1]); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	gr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "ticker,volume,open,close,high,low,window_start,transactions\n" +
+		"AAPL,12345,210.5,211,211.25,210.1,1752154200000000000,321\n"
+	if string(contents) != expected {
+		t.Errorf("Expected %q but got %q", expected, contents)
+	}
+}
+
+// TestWrite_RejectsMalformedTuples ensures that tuples with the wrong number or types of values are reported rather
+// than written.
+func TestWrite_RejectsMalformedTuples(t *testing.T) {
+	for _, bar := range [][]any{
+		{"AAPL", time.Now()},
+		{"AAPL", time.Now(), 1.0, 1.0, 1.0, 1.0, 1.0, 1},
+		{"AAPL", "2025-07-10", 1.0, 1.0, 1.0, 1.0, 1.0, int64(1)},
+	} {
+		if err := Write(io.Discard, [][]any{bar}); err == nil {
+			t.Errorf("Expected an error writing %v, got nil", bar)
+		}
+	}
+}
+
+// TestSource_ReportsInvalidRows ensures that a row that can't be parsed stops the Source with an error.
+func TestSource_ReportsInvalidRows(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, _ = io.WriteString(gw, strings.Join(Header, ",")+"\nAAPL,many,1,1,1,1,0,1\n")
+	_ = gw.Close()
+
+	s, err := NewSource(&buf)
+	if err != nil {
+		t.Fatalf("NewSource returned error: %v", err)
+	}
+	if s.Next() {
+		t.Error("Expected Next to return false for an invalid row")
+	}
+	if err := s.Err(); err == nil || !strings.Contains(err.Error(), "volume") {
+		t.Errorf("Expected an error naming the volume column, got %v", err)
+	}
+}
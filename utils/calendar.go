@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Calendar describes the trading days of a single exchange: the time zone its days are measured in, the days of the
+// week it is closed, the holidays and early closes it observes, and the time of day it closes.
+type Calendar struct {
+	Exchange   string          // The key of this exchange in Holidays
+	Location   *time.Location  // The time zone that the exchange's trading days are measured in
+	Weekend    []time.Weekday  // The days of the week on which the exchange is always closed
+	Holidays   HolidayCalendar // The source of holidays and early closes for the exchange
+	Close      time.Duration   // The offset from local midnight at which the exchange closes on a regular day
+	EarlyClose time.Duration   // The offset from local midnight at which the exchange closes on an early close day
+}
+
+// USCalendar returns the Calendar for US equity markets, which trade in Eastern Time on weekdays, observe the
+// DefaultExchange holidays, and close at 16:00 or 13:00 on early close days.
+func USCalendar() (Calendar, error) {
+	loc, err := easternLocation()
+	if err != nil {
+		return Calendar{}, fmt.Errorf("unable to load market time zone: %w", err)
+	}
+
+	return usCalendar(loc), nil
+}
+
+func usCalendar(loc *time.Location) Calendar {
+	return Calendar{
+		Exchange:   DefaultExchange,
+		Location:   loc,
+		Weekend:    []time.Weekday{time.Saturday, time.Sunday},
+		Holidays:   defaultHolidayCalendar(),
+		Close:      16 * time.Hour,
+		EarlyClose: 13 * time.Hour,
+	}
+}
+
+// maxClosedStreak is the longest run of consecutive closed days, beyond the expected 3 calendar days per trading day,
+// that LastRetainedDay will scan through before concluding that the calendar is misconfigured.
+const maxClosedStreak = 14
+
+// LastRetainedDay returns the time.Time in UTC that represents the start of the last day, in the calendar's location,
+// that should have aggregate bars retained for when `n` trading days are retained. An error is returned if `n` trading
+// days cannot be found within a reasonable number of days, such as when every day is marked as a holiday.
+func (c Calendar) LastRetainedDay(now time.Time, n uint8) (time.Time, error) {
+	var i uint8 = 0
+	today := truncateToLocationDay(now.In(c.Location))
+	curr := today
+
+	for scanned := 0; i < n; scanned++ {
+		if scanned >= 3*int(n)+maxClosedStreak {
+			return time.Time{}, fmt.Errorf("found only %d of %d market days on %s in the %d days before %s", i, n,
+				c.Exchange, scanned, today.Format(time.DateOnly))
+		}
+
+		curr = curr.AddDate(0, 0, -1)
+		if c.IsMarketOpenOnDay(curr) {
+			i++
+		}
+	}
+
+	return curr.UTC(), nil
+}
+
+// MarketDaysBetween returns the start of each day, in the calendar's location, on which the exchange is open from the
+// date of `from` to the date of `to` inclusive. Like LastRetainedDay, each day start is returned in UTC. No days are
+// returned if `from` is after `to`.
+func (c Calendar) MarketDaysBetween(from, to time.Time) []time.Time {
+	var days []time.Time
+	end := truncateToLocationDay(to.In(c.Location))
+
+	// Days are stepped with AddDate rather than by adding 24 hours, as days on which daylight saving time begins or ends
+	// are not 24 hours long.
+	for day := truncateToLocationDay(from.In(c.Location)); !day.After(end); day = day.AddDate(0, 0, 1) {
+		if c.IsMarketOpenOnDay(day) {
+			days = append(days, day.UTC())
+		}
+	}
+
+	return days
+}
+
+// IsMarketOpenOnDay checks if the date of the given time.Time, in the calendar's location, is neither a weekend nor a
+// holiday for the exchange.
+func (c Calendar) IsMarketOpenOnDay(t time.Time) bool {
+	local := t.In(c.Location)
+	return !slices.Contains(c.Weekend, local.Weekday()) && !c.IsMarketHoliday(local)
+}
+
+// IsMarketHoliday checks if the date of the given time.Time, in the calendar's location, is a holiday for the exchange.
+func (c Calendar) IsMarketHoliday(t time.Time) bool {
+	return c.Holidays.IsHoliday(c.Exchange, t.In(c.Location))
+}
+
+// IsEarlyClose checks if the date of the given time.Time, in the calendar's location, is an early close day for the
+// exchange. The date is taken in the calendar's location, as in MarketCloseTime, so that the two agree near midnight.
+func (c Calendar) IsEarlyClose(t time.Time) bool {
+	return c.Holidays.IsEarlyClose(c.Exchange, t.In(c.Location))
+}
+
+// MarketCloseTime returns the time at which the exchange closes on the date of the given time.Time in the calendar's
+// location. No check is made that the market is open on the given date.
+func (c Calendar) MarketCloseTime(t time.Time) time.Time {
+	local := t.In(c.Location)
+	closeAt := c.Close
+	if c.IsEarlyClose(local) {
+		closeAt = c.EarlyClose
+	}
+
+	// Build the close from its wall-clock components rather than adding to midnight, as days on which daylight saving
+	// time begins or ends are not 24 hours long.
+	h, m := int(closeAt/time.Hour), int(closeAt%time.Hour/time.Minute)
+	return time.Date(local.Year(), local.Month(), local.Day(), h, m, 0, 0, c.Location)
+}
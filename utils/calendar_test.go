@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// newSaudiCalendar returns a Calendar for an exchange that trades Sunday to Thursday, with Friday and Saturday as the
+// weekend, and a single holiday.
+func newSaudiCalendar(t *testing.T) Calendar {
+	t.Helper()
+	loc, err := time.LoadLocation("Asia/Riyadh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Calendar{
+		Exchange: "XSAU",
+		Location: loc,
+		Weekend:  []time.Weekday{time.Friday, time.Saturday},
+		Holidays: HolidayCalendar{"XSAU": {2025: {Holidays: []string{"2025-09-23"}}}},
+		Close:    15 * time.Hour,
+	}
+}
+
+// TestCalendar_LastRetainedDayUsesCalendarWeekend. If the current day is a Sunday on an exchange with a Friday and
+// Saturday weekend, and two business days are retained, then Wednesday and Thursday are retained.
+func TestCalendar_LastRetainedDayUsesCalendarWeekend(t *testing.T) {
+	c := newSaudiCalendar(t)
+	now := time.Date(2025, 7, 13, 12, 0, 0, 0, time.UTC)
+	expected := time.Date(2025, 7, 8, 21, 0, 0, 0, time.UTC) // Midnight on Wednesday in Riyadh (UTC+3)
+
+	result, err := c.LastRetainedDay(now, 2)
+	if err != nil {
+		t.Fatalf("LastRetainedDay returned error: %v", err)
+	}
+
+	if !result.Equal(expected) {
+		t.Errorf("Expected %v but got %v", expected, result)
+	}
+}
+
+// TestCalendar_LastRetainedDayReturnsWhenNoDaysAreOpen ensures that a pathological calendar on which the market is
+// never open produces an error rather than looping forever.
+func TestCalendar_LastRetainedDayReturnsWhenNoDaysAreOpen(t *testing.T) {
+	c := newSaudiCalendar(t)
+	c.Weekend = []time.Weekday{
+		time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.LastRetainedDay(time.Date(2025, 7, 13, 12, 0, 0, 0, time.UTC), 255)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected an error when no market days exist, got nil")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("LastRetainedDay did not return for a calendar with no market days")
+	}
+}
+
+// TestCalendar_IsMarketOpenOnDayUsesCalendarRules ensures that the calendar's weekend and holidays are applied rather
+// than the US defaults.
+func TestCalendar_IsMarketOpenOnDayUsesCalendarRules(t *testing.T) {
+	c := newSaudiCalendar(t)
+	tests := []struct {
+		day  time.Time
+		open bool
+	}{
+		{time.Date(2025, 7, 11, 0, 0, 0, 0, c.Location), false}, // Friday
+		{time.Date(2025, 7, 12, 0, 0, 0, 0, c.Location), false}, // Saturday
+		{time.Date(2025, 7, 13, 0, 0, 0, 0, c.Location), true},  // Sunday
+		{time.Date(2025, 7, 4, 0, 0, 0, 0, c.Location), false},  // Friday, and a US (but not Saudi) holiday
+		{time.Date(2025, 9, 23, 0, 0, 0, 0, c.Location), false}, // Saudi National Day, a Tuesday
+		{time.Date(2025, 7, 12, 22, 0, 0, 0, time.UTC), true},   // Saturday in UTC, but Sunday in Riyadh
+		{time.Date(2025, 9, 22, 22, 0, 0, 0, time.UTC), false},  // Monday in UTC, but Saudi National Day in Riyadh
+	}
+
+	for _, tt := range tests {
+		if got := c.IsMarketOpenOnDay(tt.day); got != tt.open {
+			t.Errorf("IsMarketOpenOnDay(%v) = %v; want %v", tt.day, got, tt.open)
+		}
+	}
+}
+
+// TestCalendar_IsMarketOpenOnDayUsesCalendarLocation ensures that the date of an instant is taken in the calendar's
+// location rather than its own, so that a UTC instant on a different local date is judged by the local date.
+func TestCalendar_IsMarketOpenOnDayUsesCalendarLocation(t *testing.T) {
+	c, err := USCalendar()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		day     time.Time
+		open    bool
+		holiday bool
+	}{
+		{time.Date(2025, 11, 29, 2, 0, 0, 0, time.UTC), true, false}, // Saturday in UTC, but Friday in New York
+		{time.Date(2025, 7, 5, 2, 0, 0, 0, time.UTC), false, true},   // Saturday in UTC, but July 4 in New York
+		{time.Date(2025, 7, 7, 2, 0, 0, 0, time.UTC), false, false},  // Monday in UTC, but Sunday in New York
+	}
+
+	for _, tt := range tests {
+		if got := c.IsMarketOpenOnDay(tt.day); got != tt.open {
+			t.Errorf("IsMarketOpenOnDay(%v) = %v; want %v", tt.day, got, tt.open)
+		}
+		if got := c.IsMarketHoliday(tt.day); got != tt.holiday {
+			t.Errorf("IsMarketHoliday(%v) = %v; want %v", tt.day, got, tt.holiday)
+		}
+	}
+}
+
+// TestCalendar_MarketCloseTimeUsesCalendarLocation ensures that the close time is expressed in the calendar's location.
+func TestCalendar_MarketCloseTimeUsesCalendarLocation(t *testing.T) {
+	c := newSaudiCalendar(t)
+	expected := time.Date(2025, 7, 13, 12, 0, 0, 0, time.UTC) // 15:00 in Riyadh (UTC+3)
+
+	if result := c.MarketCloseTime(time.Date(2025, 7, 13, 8, 0, 0, 0, time.UTC)); !result.Equal(expected) {
+		t.Errorf("Expected %v but got %v", expected, result)
+	}
+}
@@ -1,21 +1,80 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"strings"
 )
 
+// ErrNoEnvFile is returned by LoadEnvFile when there is no `.env` file to read. This is expected in deployments where
+// all configuration is supplied through real environment variables, so callers can check for it with `errors.Is`.
+var ErrNoEnvFile = errors.New("no .env file found")
+
+// LoadEnvFile reads the `./.env` file and sets each `KEY=value` pair as an environment variable. Blank lines and lines
+// beginning with `#` are skipped, and a leading `export ` is ignored so that the file can also be sourced by a shell.
+// `KEY=` sets an empty value. See parseEnvValue for how values are unquoted and stripped of comments. Variables that
+// are already present in the process environment take precedence and are left untouched, so the file acts as a set of
+// defaults.
 func LoadEnvFile() error {
-	f, err := os.ReadFile("./.env")
+	return loadEnvFile("./.env", false)
+}
+
+// LoadEnvFileWithOverwrite behaves like LoadEnvFile, except that values from the `./.env` file replace any variables
+// already present in the process environment.
+func LoadEnvFileWithOverwrite() error {
+	return loadEnvFile("./.env", true)
+}
+
+// loadEnvFile reads the env file at the given path, returning ErrNoEnvFile if it does not exist.
+func loadEnvFile(path string, overwrite bool) error {
+	f, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNoEnvFile, path)
+	}
 	if err != nil {
 		return err
 	}
 
-	ls := strings.Split(string(f), "\n")
-	for _, l := range ls {
-		ps := strings.SplitN(l, "=", 2)
+	return loadEnv(string(f), overwrite)
+}
+
+// loadEnv parses the contents of an env file and sets each variable it declares. Unless `overwrite` is true, variables
+// that are already set are skipped.
+func loadEnv(contents string, overwrite bool) error {
+	ls := strings.Split(contents, "\n")
+	for i, l := range ls {
+		// Files authored on Windows end each line with `\r\n`, which would otherwise leave a `\r` in every value.
+		l = strings.TrimSuffix(l, "\r")
+		if t := strings.TrimSpace(l); t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+
+		rk, rv, ok := strings.Cut(l, "=")
+		if !ok {
+			return fmt.Errorf("line %d: missing '=' in %q", i+1, l)
+		}
+
+		k := strings.TrimLeft(rk, " \t")
+		if rest, ok := strings.CutPrefix(k, "export"); ok && strings.IndexAny(rest, " \t") == 0 {
+			k = rest
+		}
+		k = strings.TrimSpace(k)
+		if k == "" {
+			return fmt.Errorf("line %d: missing key in %q", i+1, l)
+		}
+
+		v, err := parseEnvValue(rv)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid value for %s: %w", i+1, k, err)
+		}
+
+		if _, ok := os.LookupEnv(k); ok && !overwrite {
+			continue
+		}
 
-		err := os.Setenv(ps[0], ps[1])
+		err = os.Setenv(k, v)
 		if err != nil {
 			return err
 		}
@@ -23,3 +82,56 @@ func LoadEnvFile() error {
 
 	return nil
 }
+
+// parseEnvValue converts the raw text following the `=` on a line into the value to be set. Values wrapped in double
+// quotes have backslash escapes (`\n`, `\t`, `\"`, `\\`) applied, values wrapped in single quotes are taken literally,
+// and anything following the closing quote is ignored. Unquoted values have a trailing `# comment` removed, where the
+// `#` must be preceded by whitespace, so `abc#123` is preserved as-is.
+func parseEnvValue(raw string) (string, error) {
+	v := strings.TrimSpace(raw)
+	if v == "" {
+		return "", nil
+	}
+
+	switch v[0] {
+	case '"':
+		var sb strings.Builder
+		for i := 1; i < len(v); i++ {
+			switch v[i] {
+			case '"':
+				return sb.String(), nil
+			case '\\':
+				if i+1 < len(v) {
+					i++
+					switch v[i] {
+					case 'n':
+						sb.WriteByte('\n')
+					case 't':
+						sb.WriteByte('\t')
+					case 'r':
+						sb.WriteByte('\r')
+					default:
+						sb.WriteByte(v[i])
+					}
+				}
+			default:
+				sb.WriteByte(v[i])
+			}
+		}
+		return "", fmt.Errorf("unterminated double-quoted value %s", v)
+	case '\'':
+		end := strings.IndexByte(v[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single-quoted value %s", v)
+		}
+		return v[1 : end+1], nil
+	}
+
+	for i := 0; i < len(v); i++ {
+		if v[i] == '#' && (i == 0 || v[i-1] == ' ' || v[i-1] == '\t') {
+			return strings.TrimSpace(v[:i]), nil
+		}
+	}
+
+	return v, nil
+}
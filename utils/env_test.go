@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseEnvValue ensures that quoted values are unwrapped, escapes are honored within double quotes, and trailing
+// comments are discarded only when they fall outside of quotes.
+func TestParseEnvValue(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"plain", "abc", "abc"},
+		{"surrounding whitespace", "  abc  ", "abc"},
+		{"empty", "", ""},
+		{"double quoted", `"abc def"`, "abc def"},
+		{"single quoted", `'abc def'`, "abc def"},
+		{"double quoted with hash and comment", `"abc#123" # prod key`, "abc#123"},
+		{"single quoted with hash and comment", `'abc#123' # prod key`, "abc#123"},
+		{"double quoted escapes", `"a\"b\\c\nd"`, "a\"b\\c\nd"},
+		{"single quoted escapes are literal", `'a\nb'`, `a\nb`},
+		{"unquoted trailing comment", "abc # comment", "abc"},
+		{"unquoted hash without whitespace", "abc#123", "abc#123"},
+		{"comment only", "# comment", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEnvValue(tt.raw)
+			if err != nil {
+				t.Fatalf("parseEnvValue(%q) returned error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseEnvValue(%q) = %q; want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseEnvValue_UnterminatedQuotesError ensures that a value with an opening quote but no closing quote is rejected
+// rather than being set verbatim.
+func TestParseEnvValue_UnterminatedQuotesError(t *testing.T) {
+	for _, raw := range []string{`"abc`, `'abc`} {
+		if _, err := parseEnvValue(raw); err == nil {
+			t.Errorf("parseEnvValue(%q) expected an error, got nil", raw)
+		}
+	}
+}
+
+// TestLoadEnv_SkipsBlankAndCommentLines ensures that blank lines and full-line comments are ignored, and that the
+// remaining lines are set in the environment with their parsed values.
+func TestLoadEnv_SkipsBlankAndCommentLines(t *testing.T) {
+	unsetEnv(t, "POLYGON_API_KEY")
+	unsetEnv(t, "RETENTION_PERIOD_DAYS")
+
+	contents := "# Polygon credentials\n\nPOLYGON_API_KEY=\"abc#123\" # prod key\n   \nRETENTION_PERIOD_DAYS=14\n"
+	if err := loadEnv(contents, false); err != nil {
+		t.Fatalf("loadEnv returned error: %v", err)
+	}
+
+	if v := os.Getenv("POLYGON_API_KEY"); v != "abc#123" {
+		t.Errorf("Expected POLYGON_API_KEY to be %q, got %q", "abc#123", v)
+	}
+	if v := os.Getenv("RETENTION_PERIOD_DAYS"); v != "14" {
+		t.Errorf("Expected RETENTION_PERIOD_DAYS to be %q, got %q", "14", v)
+	}
+}
+
+// TestLoadEnvFile_MissingFileIsNotFatal ensures that a missing env file is reported with the ErrNoEnvFile sentinel so
+// that callers can distinguish it from a genuine failure.
+func TestLoadEnvFile_MissingFileIsNotFatal(t *testing.T) {
+	err := loadEnvFile(filepath.Join(t.TempDir(), ".env"), false)
+	if !errors.Is(err, ErrNoEnvFile) {
+		t.Errorf("Expected ErrNoEnvFile for a missing file, got %v", err)
+	}
+}
+
+// TestLoadEnvFile_MalformedLineErrors ensures that an env file that exists but cannot be parsed still returns an error
+// that is not mistaken for a missing file.
+func TestLoadEnvFile_MalformedLineErrors(t *testing.T) {
+	unsetEnv(t, "MALFORMED")
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("MALFORMED=\"unterminated\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := loadEnvFile(path, false)
+	if err == nil || errors.Is(err, ErrNoEnvFile) {
+		t.Errorf("Expected a parse error for a malformed line, got %v", err)
+	}
+}
+
+// TestLoadEnv_DoesNotOverwriteExistingVariables ensures that a variable already present in the environment wins over
+// the value declared in the env file, while unset variables are still populated from the file.
+func TestLoadEnv_DoesNotOverwriteExistingVariables(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://real")
+	unsetEnv(t, "RETENTION_PERIOD_DAYS")
+
+	if err := loadEnv("DATABASE_URL=postgres://file\nRETENTION_PERIOD_DAYS=14\n", false); err != nil {
+		t.Fatalf("loadEnv returned error: %v", err)
+	}
+
+	if v := os.Getenv("DATABASE_URL"); v != "postgres://real" {
+		t.Errorf("Expected DATABASE_URL to survive as %q, got %q", "postgres://real", v)
+	}
+	if v := os.Getenv("RETENTION_PERIOD_DAYS"); v != "14" {
+		t.Errorf("Expected RETENTION_PERIOD_DAYS to be %q, got %q", "14", v)
+	}
+}
+
+// TestLoadEnv_OverwritesExistingVariablesWhenRequested ensures that the env file wins over the environment when
+// `overwrite` is true.
+func TestLoadEnv_OverwritesExistingVariablesWhenRequested(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://real")
+
+	if err := loadEnv("DATABASE_URL=postgres://file\n", true); err != nil {
+		t.Fatalf("loadEnv returned error: %v", err)
+	}
+
+	if v := os.Getenv("DATABASE_URL"); v != "postgres://file" {
+		t.Errorf("Expected DATABASE_URL to be overwritten with %q, got %q", "postgres://file", v)
+	}
+}
+
+// TestLoadEnv_HandlesCRLFLineEndings ensures that files with Windows line endings do not leave a trailing carriage
+// return in any of the stored values.
+func TestLoadEnv_HandlesCRLFLineEndings(t *testing.T) {
+	unsetEnv(t, "RETENTION_PERIOD_DAYS")
+	unsetEnv(t, "POLYGON_API_KEY")
+	unsetEnv(t, "DATABASE_URL")
+
+	contents := "RETENTION_PERIOD_DAYS=14\r\nPOLYGON_API_KEY=\"abc\"\r\n\r\nDATABASE_URL='postgres://db'\r\n"
+	if err := loadEnv(contents, false); err != nil {
+		t.Fatalf("loadEnv returned error: %v", err)
+	}
+
+	for k, want := range map[string]string{
+		"RETENTION_PERIOD_DAYS": "14",
+		"POLYGON_API_KEY":       "abc",
+		"DATABASE_URL":          "postgres://db",
+	} {
+		if v := os.Getenv(k); v != want {
+			t.Errorf("Expected %s to be %q, got %q", k, want, v)
+		}
+	}
+}
+
+// TestLoadEnv_MissingEqualsErrors ensures that a line without an `=`, or without a key before it, returns an error naming
+// the offending line number and contents, rather than panicking.
+func TestLoadEnv_MissingEqualsErrors(t *testing.T) {
+	unsetEnv(t, "DATABASE_URL")
+
+	err := loadEnv("DATABASE_URL=postgres://db\n\nPOLYGONAPIKEY\n", false)
+	if err == nil {
+		t.Fatal("Expected an error for a line missing '=', got nil")
+	}
+	if !strings.Contains(err.Error(), "line 3") || !strings.Contains(err.Error(), "POLYGONAPIKEY") {
+		t.Errorf("Expected error to name line 3 and its contents, got: %v", err)
+	}
+
+	for _, line := range []string{"=value", "export =value"} {
+		err := loadEnv("DATABASE_URL=postgres://db\n"+line+"\n", false)
+		if err == nil || !strings.Contains(err.Error(), "line 2: missing key") || !strings.Contains(err.Error(), line) {
+			t.Errorf("Expected a missing key error naming line 2 for %q, got: %v", line, err)
+		}
+	}
+}
+
+// TestLoadEnv_StripsExportPrefix ensures that shell-sourceable `export KEY=value` lines set `KEY`, while a key that
+// merely begins with `export` is kept intact.
+func TestLoadEnv_StripsExportPrefix(t *testing.T) {
+	unsetEnv(t, "DATABASE_URL")
+	unsetEnv(t, "POLYGON_API_KEY")
+	unsetEnv(t, "EXPORTED")
+	unsetEnv(t, "exporter")
+
+	contents := "export DATABASE_URL=postgres://db\nexport\tPOLYGON_API_KEY=\"abc\"\nEXPORTED=1\nexporter=2\n"
+	if err := loadEnv(contents, false); err != nil {
+		t.Fatalf("loadEnv returned error: %v", err)
+	}
+
+	for k, want := range map[string]string{
+		"DATABASE_URL":    "postgres://db",
+		"POLYGON_API_KEY": "abc",
+		"EXPORTED":        "1",
+		"exporter":        "2",
+	} {
+		if v := os.Getenv(k); v != want {
+			t.Errorf("Expected %s to be %q, got %q", k, want, v)
+		}
+	}
+	if _, ok := os.LookupEnv("export DATABASE_URL"); ok {
+		t.Error("Expected the export prefix not to be kept as part of the key")
+	}
+}
+
+// TestLoadEnv_EmptyValue ensures that `KEY=` sets the variable to an empty string rather than leaving it unset.
+func TestLoadEnv_EmptyValue(t *testing.T) {
+	unsetEnv(t, "POLYGON_API_KEY")
+	unsetEnv(t, "DATABASE_URL")
+
+	if err := loadEnv("POLYGON_API_KEY=\nexport DATABASE_URL=\n", false); err != nil {
+		t.Fatalf("loadEnv returned error: %v", err)
+	}
+
+	for _, k := range []string{"POLYGON_API_KEY", "DATABASE_URL"} {
+		if v, ok := os.LookupEnv(k); !ok || v != "" {
+			t.Errorf("Expected %s to be set to an empty string, got %q (set: %t)", k, v, ok)
+		}
+	}
+}
+
+// unsetEnv removes a variable from the environment for the duration of a test, restoring its original value after.
+func unsetEnv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	if err := os.Unsetenv(key); err != nil {
+		t.Fatal(err)
+	}
+}
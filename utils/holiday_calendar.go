@@ -0,0 +1,103 @@
+package utils
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultExchange is the exchange whose calendar is consulted by IsMarketHoliday.
+const DefaultExchange = "NYSE"
+
+// builtinHolidays is the multi-year holiday dataset compiled into the binary. This data is sourced manually from
+// https://www.nasdaq.com/market-activity/stock-market-holiday-schedule and should be extended as each year's schedule
+// is published.
+//
+//go:embed holidays.json
+var builtinHolidays []byte
+
+// HolidayCalendar holds the market calendar for each exchange, keyed by exchange name and then by year.
+type HolidayCalendar map[string]map[int]MarketYear
+
+// MarketYear is a single year of an exchange's calendar. Dates are stored in `YYYY-MM-DD` format.
+type MarketYear struct {
+	Holidays    []string `json:"holidays"`
+	EarlyCloses []string `json:"early_closes"`
+}
+
+// ParseHolidayCalendar parses a JSON-encoded HolidayCalendar, validating that every holiday and early close date is
+// well-formed and falls in the year it is listed under.
+func ParseHolidayCalendar(data []byte) (HolidayCalendar, error) {
+	var c HolidayCalendar
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("unable to parse holiday calendar: %w", err)
+	}
+
+	for exchange, years := range c {
+		for year, y := range years {
+			for _, h := range slices.Concat(y.Holidays, y.EarlyCloses) {
+				d, err := time.Parse(time.DateOnly, h)
+				if err != nil {
+					return nil, fmt.Errorf("invalid date %q for %s: %w", h, exchange, err)
+				}
+				if d.Year() != year {
+					return nil, fmt.Errorf("date %s for %s is listed under year %d", h, exchange, year)
+				}
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// LoadHolidayCalendar reads and parses a JSON-encoded HolidayCalendar from the file at the given path.
+func LoadHolidayCalendar(path string) (HolidayCalendar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read holiday calendar %s: %w", path, err)
+	}
+
+	return ParseHolidayCalendar(data)
+}
+
+// HasYear reports whether the calendar contains any data for the given exchange and year. Dates in years without data
+// are never considered holidays, so callers can use this to detect a calendar that needs updating.
+func (c HolidayCalendar) HasYear(exchange string, year int) bool {
+	_, ok := c[exchange][year]
+	return ok
+}
+
+// IsHoliday reports whether the date of the given time.Time, in its own location, is a holiday on the given exchange.
+func (c HolidayCalendar) IsHoliday(exchange string, t time.Time) bool {
+	return slices.Contains(c[exchange][t.Year()].Holidays, t.Format(time.DateOnly))
+}
+
+// IsEarlyClose reports whether the date of the given time.Time, in its own location, is an early close (half day) on the
+// given exchange.
+func (c HolidayCalendar) IsEarlyClose(exchange string, t time.Time) bool {
+	return slices.Contains(c[exchange][t.Year()].EarlyCloses, t.Format(time.DateOnly))
+}
+
+// defaultHolidayCalendar returns the calendar used by IsMarketHoliday. If the `MARKET_HOLIDAYS_FILE` environment
+// variable names a JSON calendar file, that file is used. Otherwise, or if the file cannot be loaded, the built-in
+// dataset is used.
+var defaultHolidayCalendar = sync.OnceValue(func() HolidayCalendar {
+	if path := os.Getenv("MARKET_HOLIDAYS_FILE"); path != "" {
+		c, err := LoadHolidayCalendar(path)
+		if err == nil {
+			return c
+		}
+		fmt.Printf("Falling back to built-in holiday calendar: %v\n", err)
+	}
+
+	c, err := ParseHolidayCalendar(builtinHolidays)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+})
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestIsMarketHoliday_Recognises2026Holidays ensures that the built-in calendar covers years beyond 2025, so that a
+// backfill spanning into 2026 skips its holidays.
+func TestIsMarketHoliday_Recognises2026Holidays(t *testing.T) {
+	goodFriday := time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC)
+	if !IsMarketHoliday(goodFriday) {
+		t.Errorf("Expected %v to be a market holiday", goodFriday)
+	}
+
+	nextDay := time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC)
+	if IsMarketHoliday(nextDay) {
+		t.Errorf("Expected %v not to be a market holiday", nextDay)
+	}
+}
+
+// TestIsMarketHoliday_UnknownYearIsNotAHoliday ensures that dates in a year the calendar has no data for are treated
+// as ordinary days, and that this gap can be detected with HasYear.
+func TestIsMarketHoliday_UnknownYearIsNotAHoliday(t *testing.T) {
+	newYears := time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC)
+	if IsMarketHoliday(newYears) {
+		t.Errorf("Expected %v not to be a market holiday in a year without calendar data", newYears)
+	}
+	if defaultHolidayCalendar().HasYear(DefaultExchange, 2040) {
+		t.Errorf("Expected the built-in calendar to have no data for 2040")
+	}
+}
+
+// TestLoadHolidayCalendar_ReadsExchangeAndYear ensures that a calendar loaded from a file is keyed by exchange and year.
+func TestLoadHolidayCalendar_ReadsExchangeAndYear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.json")
+	data := `{"TSX": {"2026": {"holidays": ["2026-07-01"]}}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadHolidayCalendar(path)
+	if err != nil {
+		t.Fatalf("LoadHolidayCalendar returned error: %v", err)
+	}
+
+	canadaDay := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	if !c.IsHoliday("TSX", canadaDay) {
+		t.Errorf("Expected %v to be a holiday on TSX", canadaDay)
+	}
+	if c.IsHoliday(DefaultExchange, canadaDay) {
+		t.Errorf("Expected %v not to be a holiday on %s", canadaDay, DefaultExchange)
+	}
+}
+
+// TestParseHolidayCalendar_RejectsInvalidDates ensures that malformed dates, or dates listed under the wrong year, are
+// rejected rather than silently never matching.
+func TestParseHolidayCalendar_RejectsInvalidDates(t *testing.T) {
+	for _, data := range []string{
+		`{"NYSE": {"2026": {"holidays": ["04 April 2026"]}}}`,
+		`{"NYSE": {"2026": {"holidays": ["2027-01-01"]}}}`,
+	} {
+		if _, err := ParseHolidayCalendar([]byte(data)); err == nil {
+			t.Errorf("ParseHolidayCalendar(%s) expected an error, got nil", data)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"sync"
+	"time"
+
+	// Embeds the zoneinfo database in the binary so that time zones resolve in containers that ship without tzdata.
+	_ "time/tzdata"
+)
+
+// easternLocation returns the America/New_York location that US market days are measured in. The location is loaded
+// once and reused for all subsequent calls. It is a variable so that tests can simulate a location-loading failure.
+var easternLocation = sync.OnceValues(func() (*time.Location, error) {
+	return time.LoadLocation("America/New_York")
+})
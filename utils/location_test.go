@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// TestEasternLocation_HasCorrectOffsets ensures that the Eastern Time location resolves and observes both EST (UTC-5)
+// in winter and EDT (UTC-4) in summer.
+func TestEasternLocation_HasCorrectOffsets(t *testing.T) {
+	loc, err := easternLocation()
+	if err != nil {
+		t.Fatalf("easternLocation returned error: %v", err)
+	}
+	if loc == nil {
+		t.Fatal("easternLocation returned a nil location")
+	}
+
+	for _, tt := range []struct {
+		t      time.Time
+		offset int
+	}{
+		{time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC), -5 * 60 * 60},
+		{time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC), -4 * 60 * 60},
+	} {
+		if _, offset := tt.t.In(loc).Zone(); offset != tt.offset {
+			t.Errorf("Expected offset %d at %v, got %d", tt.offset, tt.t, offset)
+		}
+	}
+}
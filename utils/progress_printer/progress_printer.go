@@ -3,34 +3,149 @@ package progress_printer
 import (
 	"fmt"
 	"io"
+	"os"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
 )
 
-// ProgressPrinter is a utility for printing progress messages that overwrite previous messages in the terminal.
+// barWidth is the number of characters between the brackets of a bar rendered by UpdateProgress.
+const barWidth = 20
+
+// spinnerFrames are the characters cycled through by Tick when the spinner is enabled with WithSpinner.
+const spinnerFrames = `|/-\`
+
+// ProgressPrinter is a utility for printing progress messages that overwrite previous messages in the terminal. When
+// not writing to a terminal, each message is instead printed on its own line. It is safe for concurrent use, with each
+// message written in full before the next begins.
 type ProgressPrinter struct {
-	w   io.Writer // The writer to which messages are printed
-	max int       // Tracks the maximum line length that's been printed
+	mu  sync.Mutex // Serializes writes and updates to max
+	w   io.Writer  // The writer to which messages are printed
+	max int        // Tracks the maximum line width, in columns, that's been printed
+	tty bool       // Whether messages overwrite each other using carriage returns
+
+	spinner bool             // Whether messages are prefixed with a spinner and the elapsed time
+	frame   int              // The index of the current spinner frame in spinnerFrames
+	start   time.Time        // When the spinner was enabled, from which the elapsed time is measured
+	message string           // The last message printed, which Tick prints again with a new prefix
+	now     func() time.Time // Returns the current time, which can be replaced in tests
 }
 
+// NewProgressPrinter creates a ProgressPrinter for the given writer. If the writer is an `*os.File`, such as stdout,
+// messages only overwrite each other when it is a terminal, so that redirected output isn't garbled into a single line.
+// Other writers are assumed to be terminals; use NewProgressPrinterWithTTY to choose explicitly.
 func NewProgressPrinter(w io.Writer) *ProgressPrinter {
-	return &ProgressPrinter{max: 0, w: w}
+	tty := true
+	if f, ok := w.(*os.File); ok {
+		tty = term.IsTerminal(int(f.Fd()))
+	}
+
+	return NewProgressPrinterWithTTY(w, tty)
+}
+
+// NewProgressPrinterWithTTY creates a ProgressPrinter that overwrites previous messages if `tty` is true, or prints
+// each message on its own line otherwise.
+func NewProgressPrinterWithTTY(w io.Writer, tty bool) *ProgressPrinter {
+	return &ProgressPrinter{max: 0, w: w, tty: tty, now: time.Now}
+}
+
+// WithSpinner enables a prefix of a rotating spinner and the time elapsed since it was enabled, such as `| 01:05 `, on
+// every message. Calling Tick periodically advances the spinner and refreshes the elapsed time even when the message
+// doesn't change, so that long silent stretches don't look frozen. The printer is returned to allow chaining.
+func (p *ProgressPrinter) WithSpinner() *ProgressPrinter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.spinner = true
+	p.start = p.now()
+
+	return p
+}
+
+// Tick advances the spinner and reprints the last message with the updated elapsed time. It does nothing unless the
+// spinner is enabled and the printer is writing to a terminal, as reprinting would otherwise repeat the message on a new
+// line.
+func (p *ProgressPrinter) Tick() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.spinner || !p.tty {
+		return
+	}
+
+	p.frame = (p.frame + 1) % len(spinnerFrames)
+	p.update(p.message)
 }
 
 // Update prints a progress message that overwrites the previous message.
-// It keeps track of the maximum line length to ensure proper clearing of previous content.
+// It keeps track of the maximum line width to ensure proper clearing of previous content. Widths are measured in
+// terminal columns rather than bytes, so that multibyte characters such as accented letters or emoji are padded
+// correctly.
 func (p *ProgressPrinter) Update(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.update(message)
+}
+
+// update performs the work of Update, and must be called while holding the mutex.
+func (p *ProgressPrinter) update(message string) {
+	p.message = message
+	if p.spinner {
+		message = fmt.Sprintf("%c %s %s", spinnerFrames[p.frame], formatElapsed(p.now().Sub(p.start)), message)
+	}
+
+	if !p.tty {
+		_, _ = fmt.Fprintln(p.w, message)
+		return
+	}
+
+	width := runewidth.StringWidth(message)
+
 	// Clear the previous line by printing spaces
-	_, _ = fmt.Fprint(p.w, message+strings.Repeat(" ", max(0, p.max-len(message)))+"\r")
+	_, _ = fmt.Fprint(p.w, message+strings.Repeat(" ", max(0, p.max-width))+"\r")
 
-	// Update the max length if this message is longer
-	if len(message) > p.max {
-		p.max = len(message)
+	// Update the max width if this message is wider
+	if width > p.max {
+		p.max = width
 	}
 }
 
 // Complete prints a final message and adds a newline. Use this when the progress is complete, and you want to move to
 // the next line.
 func (p *ProgressPrinter) Complete(message string) {
-	p.Update(message)
-	_, _ = fmt.Fprintln(p.w)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.update(message)
+	if p.tty {
+		_, _ = fmt.Fprintln(p.w)
+	}
+}
+
+// UpdateProgress prints a progress bar followed by a percentage and label, such as `[##########----------] 50% label`,
+// overwriting the previous message. The `current` value is clamped to the range `[0, total]`, and a `total` of zero or
+// less renders an empty bar at 0%.
+func (p *ProgressPrinter) UpdateProgress(current, total int, label string) {
+	ratio := 0.0
+	if total > 0 {
+		ratio = float64(min(max(current, 0), total)) / float64(total)
+	}
+
+	filled := int(ratio * barWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", barWidth-filled)
+	p.Update(fmt.Sprintf("[%s] %d%% %s", bar, int(ratio*100), label))
+}
+
+// formatElapsed formats a duration as minutes and seconds, such as `01:05`, including hours only once an hour has
+// elapsed, such as `1:02:05`.
+func formatElapsed(d time.Duration) string {
+	s := int(d.Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
 }
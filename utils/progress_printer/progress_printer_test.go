@@ -2,8 +2,12 @@ package progress_printer
 
 import (
 	"bytes"
+	"os"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // TestProgressPrinter_UpdateIncreasesMaxLength ensures that if a longer message is printed after a shorter one,
@@ -91,3 +95,189 @@ func TestProgressPrinter_PreviousUpdatesAreOverwritten(t *testing.T) {
 		t.Errorf("Expected output to end with newline, got: %q", out)
 	}
 }
+
+// TestProgressPrinter_UpdateProgressRendersBar ensures that the rendered bar is always the same width, is filled in
+// proportion to the ratio, and that out-of-range values are clamped.
+func TestProgressPrinter_UpdateProgressRendersBar(t *testing.T) {
+	tests := []struct {
+		name           string
+		current, total int
+		want           string
+	}{
+		{"empty", 0, 10, "[--------------------] 0% days"},
+		{"quarter", 1, 4, "[#####---------------] 25% days"},
+		{"half", 5, 10, "[##########----------] 50% days"},
+		{"complete", 14, 14, "[####################] 100% days"},
+		{"over total", 20, 10, "[####################] 100% days"},
+		{"negative", -5, 10, "[--------------------] 0% days"},
+		{"zero total", 3, 0, "[--------------------] 0% days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			NewProgressPrinter(&buf).UpdateProgress(tt.current, tt.total, "days")
+
+			if out := buf.String(); out != tt.want+"\r" {
+				t.Errorf("UpdateProgress(%d, %d) printed %q; want %q", tt.current, tt.total, out, tt.want+"\r")
+			}
+		})
+	}
+}
+
+// TestProgressPrinter_NonTTYPrintsLines ensures that when not writing to a terminal, each message is printed on its
+// own line without carriage returns or padding, and that Complete doesn't add a blank line.
+func TestProgressPrinter_NonTTYPrintsLines(t *testing.T) {
+	var buf bytes.Buffer
+	pp := NewProgressPrinterWithTTY(&buf, false)
+
+	pp.Update("Longer message")
+	pp.Update("Short")
+	pp.Complete("Done")
+
+	if out := buf.String(); out != "Longer message\nShort\nDone\n" {
+		t.Errorf("Expected one line per message, got: %q", out)
+	}
+}
+
+// TestProgressPrinter_TTYOverwritesLines ensures that when forced into TTY mode, messages overwrite one another using
+// carriage returns.
+func TestProgressPrinter_TTYOverwritesLines(t *testing.T) {
+	var buf bytes.Buffer
+	pp := NewProgressPrinterWithTTY(&buf, true)
+
+	pp.Update("First")
+	pp.Complete("Done")
+
+	if out := buf.String(); out != "First\rDone \r\n" {
+		t.Errorf("Expected overwritten output, got: %q", out)
+	}
+}
+
+// TestNewProgressPrinter_DetectsNonTTYFile ensures that a regular file, such as stdout redirected to a log, is detected
+// as not being a terminal.
+func TestNewProgressPrinter_DetectsNonTTYFile(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if NewProgressPrinter(f).tty {
+		t.Error("Expected a regular file not to be detected as a terminal")
+	}
+}
+
+// TestProgressPrinter_MultibyteMessagesUseDisplayWidth ensures that padding is computed from a message's display width
+// rather than its byte length, so a multibyte message after a longer ASCII message clears it exactly.
+func TestProgressPrinter_MultibyteMessagesUseDisplayWidth(t *testing.T) {
+	var buf bytes.Buffer
+	pp := NewProgressPrinter(&buf)
+
+	pp.Update("Longer message")
+	pp.Update("Société")
+
+	// "Société" is 9 bytes but 7 columns wide, so 7 spaces are needed to clear the 14 column message.
+	if out := buf.String(); out != "Longer message\rSociété       \r" {
+		t.Errorf("Expected the previous message to be cleared exactly, got: %q", out)
+	}
+	if pp.max != 14 {
+		t.Errorf("Expected max to remain 14, got %d", pp.max)
+	}
+
+	// Emoji occupy two columns each.
+	pp.Update(strings.Repeat("🚀", 8))
+	if pp.max != 16 {
+		t.Errorf("Expected max to be 16 after printing 8 emoji, got %d", pp.max)
+	}
+}
+
+// TestProgressPrinter_ConcurrentUpdatesAreSerialized ensures that concurrent callers never interleave their writes, and
+// that the final max is the width of the longest message printed by any of them.
+func TestProgressPrinter_ConcurrentUpdatesAreSerialized(t *testing.T) {
+	var buf bytes.Buffer
+	pp := NewProgressPrinter(&buf)
+
+	messages := []string{"aaaa", "bbbbbbbb", "cccccccccccc", "dddddddddddddddd"}
+	var wg sync.WaitGroup
+	for _, msg := range messages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				pp.Update(msg)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r"), "\r") {
+		trimmed := strings.TrimRight(line, " ")
+		if !slices.Contains(messages, trimmed) {
+			t.Fatalf("Found torn output %q", line)
+		}
+	}
+	if pp.max != len(messages[len(messages)-1]) {
+		t.Errorf("Expected max to be %d, got %d", len(messages[len(messages)-1]), pp.max)
+	}
+}
+
+// TestProgressPrinter_TickAdvancesSpinner ensures that each Tick reprints the last message with the next spinner frame
+// and the updated elapsed time, and that the prefix is included when clearing longer lines.
+func TestProgressPrinter_TickAdvancesSpinner(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2025, 7, 10, 9, 30, 0, 0, time.UTC)
+	pp := NewProgressPrinterWithTTY(&buf, true)
+	pp.now = func() time.Time { return now }
+	pp.WithSpinner()
+
+	pp.Update("Downloading")
+	now = now.Add(5 * time.Second)
+	pp.Tick()
+	now = now.Add(60 * time.Second)
+	pp.Tick()
+	pp.Update("Done")
+
+	expected := "| 00:00 Downloading\r" +
+		"/ 00:05 Downloading\r" +
+		"- 01:05 Downloading\r" +
+		"- 01:05 Done       \r"
+	if out := buf.String(); out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+	if pp.max != len("| 00:00 Downloading") {
+		t.Errorf("Expected max to include the prefix, got %d", pp.max)
+	}
+}
+
+// TestProgressPrinter_TickWithoutSpinner ensures that Tick prints nothing unless the spinner is enabled and the output
+// is a terminal.
+func TestProgressPrinter_TickWithoutSpinner(t *testing.T) {
+	for _, pp := range []*ProgressPrinter{
+		NewProgressPrinterWithTTY(&bytes.Buffer{}, true),
+		NewProgressPrinterWithTTY(&bytes.Buffer{}, false).WithSpinner(),
+	} {
+		pp.Update("Downloading")
+		buf := pp.w.(*bytes.Buffer)
+		before := buf.Len()
+
+		pp.Tick()
+		if buf.Len() != before {
+			t.Errorf("Expected Tick to print nothing, got %q", buf.String()[before:])
+		}
+	}
+}
+
+// TestFormatElapsed ensures that elapsed times are formatted as minutes and seconds, with hours added after an hour.
+func TestFormatElapsed(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                       "00:00",
+		1500 * time.Millisecond: "00:01",
+		65 * time.Second:        "01:05",
+		time.Hour + 2*time.Minute + 5*time.Second: "1:02:05",
+	} {
+		if got := formatElapsed(d); got != want {
+			t.Errorf("formatElapsed(%v) = %q; want %q", d, got, want)
+		}
+	}
+}
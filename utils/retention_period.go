@@ -1,68 +1,66 @@
 package utils
 
 import (
-	"fmt"
-	"os"
 	"time"
 )
 
 // LastRetainedDay returns the time.Time in UTC that represents the start of the last day in Eastern Time that should
-// have aggregate bars retained for.
-func LastRetainedDay(now time.Time, n uint8) time.Time {
-	loc, err := time.LoadLocation("America/New_York")
+// have aggregate bars retained for. An error is returned if the Eastern Time zone cannot be loaded, or if `n` market
+// days cannot be found. Use Calendar.LastRetainedDay for exchanges other than the DefaultExchange.
+func LastRetainedDay(now time.Time, n uint8) (time.Time, error) {
+	c, err := USCalendar()
 	if err != nil {
-		panic(err)
+		return time.Time{}, err
 	}
 
-	var i uint8 = 0
-	today := truncateToLocationDay(now.In(loc))
-	curr := today
+	return c.LastRetainedDay(now, n)
+}
 
-	for i < n {
-		curr = curr.AddDate(0, 0, -1)
-		if IsMarketOpenOnDay(curr) {
-			i++
-		}
+// MarketDaysBetween returns the start of each market day in Eastern Time, as a time.Time in UTC, from the date of
+// `from` to the date of `to` inclusive. An error is returned if the Eastern Time zone cannot be loaded.
+func MarketDaysBetween(from, to time.Time) ([]time.Time, error) {
+	c, err := USCalendar()
+	if err != nil {
+		return nil, err
 	}
 
-	return curr.UTC()
+	return c.MarketDaysBetween(from, to), nil
 }
 
 // IsMarketOpenOnDay checks if the given time.Time instance is neither a weekend nor a market holiday, thus data is
 // assumed to be present for the given time.Time's date if `true` is returned.
 func IsMarketOpenOnDay(t time.Time) bool {
-	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday && !IsMarketHoliday(t)
+	return usCalendar(t.Location()).IsMarketOpenOnDay(t)
 }
 
-// IsMarketHoliday checks if the given time.Time instance is on the same date as any of the listed market holidays in
-// the `holidays` slice. This data is sourced manually from https://www.nasdaq.com/market-activity/stock-market-holiday-schedule
-// and should be updated annually. Note that early close dates are not considered holidays.
+// IsMarketHoliday checks if the given time.Time instance is on the same date as any of the market holidays listed for
+// the DefaultExchange in the default HolidayCalendar. Dates in years the calendar has no data for are not considered
+// holidays. Note that early close dates are not considered holidays.
 func IsMarketHoliday(t time.Time) bool {
-	holidays := []string{
-		"01 January 2025",
-		"20 January 2025",
-		"17 February 2025",
-		"18 April 2025",
-		"26 May 2025",
-		"19 June 2025",
-		"04 July 2025",
-		"01 September 2025",
-		"27 November 2025",
-		"25 December 2025",
+	return usCalendar(t.Location()).IsMarketHoliday(t)
+}
+
+// IsEarlyClose checks if the Eastern Time date of the given time.Time is an early close (half) day for the
+// DefaultExchange in the default HolidayCalendar, such as the day after Thanksgiving. It uses the same calendar as
+// MarketCloseTime, so the two always agree. An error is returned if the Eastern Time zone cannot be loaded.
+func IsEarlyClose(t time.Time) (bool, error) {
+	c, err := USCalendar()
+	if err != nil {
+		return false, err
 	}
 
-	for _, h := range holidays {
-		ht, err := time.ParseInLocation("02 January 2006", h, t.Location())
-		if err != nil {
-			fmt.Printf("Unable to parse holiday date %s\n", h)
-			os.Exit(1)
-		}
-		if t.Year() == ht.Year() && t.Month() == ht.Month() && t.Day() == ht.Day() {
-			return true
-		}
+	return c.IsEarlyClose(t), nil
+}
+
+// MarketCloseTime returns the time at which the market closes on the Eastern Time date of the given time.Time. This is
+// 13:00 ET on early close days and 16:00 ET otherwise. No check is made that the market is open on the given date.
+func MarketCloseTime(t time.Time) (time.Time, error) {
+	c, err := USCalendar()
+	if err != nil {
+		return time.Time{}, err
 	}
 
-	return false
+	return c.MarketCloseTime(t), nil
 }
 
 func truncateToLocationDay(t time.Time) time.Time {
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -10,7 +11,10 @@ import (
 func TestLastRetainedDay_IsAThursdayIfGivenASunday(t *testing.T) {
 	now := time.Date(2025, 7, 13, 0, 0, 0, 0, time.UTC)
 	expected := time.Date(2025, 7, 10, 4, 0, 0, 0, time.UTC) // Thursday before the weekend, in UTC.
-	result := LastRetainedDay(now, 2)
+	result, err := LastRetainedDay(now, 2)
+	if err != nil {
+		t.Fatalf("LastRetainedDay returned error: %v", err)
+	}
 
 	if !result.Equal(expected) {
 		t.Errorf("Expected %v but got %v", expected, result)
@@ -22,9 +26,273 @@ func TestLastRetainedDay_IsAThursdayIfGivenASunday(t *testing.T) {
 func TestLastRetainedDay_IsAWednesdayIfGivenAFriday(t *testing.T) {
 	now := time.Date(2025, 7, 11, 12, 0, 0, 0, time.UTC)
 	expected := time.Date(2025, 7, 9, 4, 0, 0, 0, time.UTC) // Friday before the weekend
-	result := LastRetainedDay(now, 2)
+	result, err := LastRetainedDay(now, 2)
+	if err != nil {
+		t.Fatalf("LastRetainedDay returned error: %v", err)
+	}
+
+	if !result.Equal(expected) {
+		t.Errorf("Expected %v but got %v", expected, result)
+	}
+}
+
+// TestLastRetainedDay_IsTodayIfNoDaysAreRetained. If zero business days are retained, then the start of the current day
+// in Eastern Time should be returned, regardless of the time of day.
+func TestLastRetainedDay_IsTodayIfNoDaysAreRetained(t *testing.T) {
+	now := time.Date(2025, 7, 11, 12, 0, 0, 0, time.UTC)
+	expected := time.Date(2025, 7, 11, 4, 0, 0, 0, time.UTC) // Midnight on Friday in Eastern Time
+	result, err := LastRetainedDay(now, 0)
+	if err != nil {
+		t.Fatalf("LastRetainedDay returned error: %v", err)
+	}
 
 	if !result.Equal(expected) {
 		t.Errorf("Expected %v but got %v", expected, result)
 	}
 }
+
+// TestLastRetainedDay_SkipsHolidays. If the current day is the Monday after Independence Day (a Friday), and two
+// business days are retained, then Wednesday and Thursday are retained, as the holiday and weekend are ignored.
+func TestLastRetainedDay_SkipsHolidays(t *testing.T) {
+	now := time.Date(2025, 7, 7, 12, 0, 0, 0, time.UTC)
+	expected := time.Date(2025, 7, 2, 4, 0, 0, 0, time.UTC) // Wednesday before Independence Day
+	result, err := LastRetainedDay(now, 2)
+	if err != nil {
+		t.Fatalf("LastRetainedDay returned error: %v", err)
+	}
+
+	if !result.Equal(expected) {
+		t.Errorf("Expected %v but got %v", expected, result)
+	}
+}
+
+// TestLastRetainedDay_ReturnsErrorWhenLocationUnavailable ensures that a failure to load the Eastern Time zone (such as
+// in a container without tzdata) is returned as an error instead of panicking.
+func TestLastRetainedDay_ReturnsErrorWhenLocationUnavailable(t *testing.T) {
+	loadErr := errors.New("unknown time zone America/New_York")
+	original := easternLocation
+	easternLocation = func() (*time.Location, error) { return nil, loadErr }
+	t.Cleanup(func() { easternLocation = original })
+
+	_, err := LastRetainedDay(time.Date(2025, 7, 13, 0, 0, 0, 0, time.UTC), 2)
+	if !errors.Is(err, loadErr) {
+		t.Errorf("Expected error wrapping %v, got %v", loadErr, err)
+	}
+
+	_, err = IsEarlyClose(time.Date(2025, 11, 28, 15, 0, 0, 0, time.UTC))
+	if !errors.Is(err, loadErr) {
+		t.Errorf("Expected IsEarlyClose error wrapping %v, got %v", loadErr, err)
+	}
+}
+
+// TestMarketCloseTime_EarlyCloseDays ensures that the day after Thanksgiving and Christmas Eve close at 13:00 ET, while
+// an ordinary trading day closes at 16:00 ET, and that IsEarlyClose agrees with MarketCloseTime near midnight UTC.
+func TestMarketCloseTime_EarlyCloseDays(t *testing.T) {
+	tests := []struct {
+		name      string
+		now       time.Time
+		early     bool
+		wantClose time.Time
+	}{
+		{
+			name:      "day after Thanksgiving",
+			now:       time.Date(2025, 11, 28, 15, 0, 0, 0, time.UTC),
+			early:     true,
+			wantClose: time.Date(2025, 11, 28, 18, 0, 0, 0, time.UTC), // 13:00 EST
+		},
+		{
+			name:      "day after Thanksgiving, after midnight UTC",
+			now:       time.Date(2025, 11, 29, 2, 0, 0, 0, time.UTC), // 21:00 EST on the 28th
+			early:     true,
+			wantClose: time.Date(2025, 11, 28, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "ordinary day, before midnight UTC on an early close",
+			now:       time.Date(2025, 12, 24, 3, 0, 0, 0, time.UTC), // 22:00 EST on the 23rd
+			early:     false,
+			wantClose: time.Date(2025, 12, 23, 21, 0, 0, 0, time.UTC), // 16:00 EST
+		},
+		{
+			name:      "Christmas Eve",
+			now:       time.Date(2025, 12, 24, 15, 0, 0, 0, time.UTC),
+			early:     true,
+			wantClose: time.Date(2025, 12, 24, 18, 0, 0, 0, time.UTC), // 13:00 EST
+		},
+		{
+			name:      "ordinary trading day",
+			now:       time.Date(2025, 7, 11, 15, 0, 0, 0, time.UTC),
+			early:     false,
+			wantClose: time.Date(2025, 7, 11, 20, 0, 0, 0, time.UTC), // 16:00 EDT
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			early, err := IsEarlyClose(tt.now)
+			if err != nil {
+				t.Fatalf("IsEarlyClose returned error: %v", err)
+			}
+			if early != tt.early {
+				t.Errorf("IsEarlyClose(%v) = %v; want %v", tt.now, early, tt.early)
+			}
+
+			got, err := MarketCloseTime(tt.now)
+			if err != nil {
+				t.Fatalf("MarketCloseTime returned error: %v", err)
+			}
+			if !got.Equal(tt.wantClose) {
+				t.Errorf("MarketCloseTime(%v) = %v; want %v", tt.now, got, tt.wantClose)
+			}
+		})
+	}
+}
+
+// TestMarketDaysBetween ensures that only market days are returned between the Eastern Time dates of the bounds
+// inclusive, skipping weekends and holidays, and that each day starts at midnight in Eastern Time on either side of a
+// daylight saving time transition.
+func TestMarketDaysBetween(t *testing.T) {
+	et := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Add(5 * time.Hour)
+	}
+	edt := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Add(4 * time.Hour)
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		expected []time.Time
+	}{
+		{
+			name: "weekend",
+			from: time.Date(2025, 7, 10, 20, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, 7, 15, 2, 0, 0, 0, time.UTC), // Still the 14th in Eastern Time
+			expected: []time.Time{
+				edt(2025, 7, 10), edt(2025, 7, 11), edt(2025, 7, 14),
+			},
+		},
+		{
+			name:     "holiday",
+			from:     time.Date(2025, 7, 3, 12, 0, 0, 0, time.UTC),
+			to:       time.Date(2025, 7, 7, 12, 0, 0, 0, time.UTC),
+			expected: []time.Time{edt(2025, 7, 3), edt(2025, 7, 7)},
+		},
+		{
+			name: "daylight saving time begins",
+			from: time.Date(2025, 3, 6, 12, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				et(2025, 3, 6), et(2025, 3, 7), edt(2025, 3, 10), edt(2025, 3, 11),
+			},
+		},
+		{
+			name: "daylight saving time ends",
+			from: time.Date(2025, 10, 31, 12, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, 11, 4, 12, 0, 0, 0, time.UTC),
+			expected: []time.Time{
+				edt(2025, 10, 31), et(2025, 11, 3), et(2025, 11, 4),
+			},
+		},
+		{
+			name:     "single day",
+			from:     time.Date(2025, 7, 10, 14, 0, 0, 0, time.UTC),
+			to:       time.Date(2025, 7, 10, 15, 0, 0, 0, time.UTC),
+			expected: []time.Time{edt(2025, 7, 10)},
+		},
+		{
+			name: "from after to",
+			from: time.Date(2025, 7, 14, 12, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, err := MarketDaysBetween(tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("MarketDaysBetween returned error: %v", err)
+			}
+
+			if len(days) != len(tt.expected) {
+				t.Fatalf("Expected %v but got %v", tt.expected, days)
+			}
+			for i := range days {
+				if !days[i].Equal(tt.expected[i]) {
+					t.Errorf("Expected %v but got %v", tt.expected, days)
+					break
+				}
+			}
+		})
+	}
+}
+
+// TestLastRetainedDay_AcrossDaylightSavingTime ensures that the retained day is always the true midnight in Eastern
+// Time, whether the 23 hour day on which daylight saving time begins or the 25 hour day on which it ends is crossed.
+func TestLastRetainedDay_AcrossDaylightSavingTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		n        uint8
+		expected time.Time
+	}{
+		// 2025-03-09 began daylight saving time at 02:00 EST.
+		{
+			name:     "spring forward, same day",
+			now:      time.Date(2025, 3, 9, 7, 30, 0, 0, time.UTC),
+			n:        0,
+			expected: time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "spring forward, day after",
+			now:      time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+			n:        1,
+			expected: time.Date(2025, 3, 7, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "spring forward, two days after",
+			now:      time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC),
+			n:        1,
+			expected: time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC),
+		},
+		// 2025-11-02 ended daylight saving time at 02:00 EDT.
+		{
+			name:     "fall back, same day",
+			now:      time.Date(2025, 11, 2, 12, 0, 0, 0, time.UTC),
+			n:        0,
+			expected: time.Date(2025, 11, 2, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "fall back, day after",
+			now:      time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC),
+			n:        1,
+			expected: time.Date(2025, 10, 31, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "fall back, two days after",
+			now:      time.Date(2025, 11, 4, 12, 0, 0, 0, time.UTC),
+			n:        1,
+			expected: time.Date(2025, 11, 3, 5, 0, 0, 0, time.UTC),
+		},
+	}
+
+	loc, err := easternLocation()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := LastRetainedDay(tt.now, tt.n)
+			if err != nil {
+				t.Fatalf("LastRetainedDay returned error: %v", err)
+			}
+
+			if !result.Equal(tt.expected) {
+				t.Errorf("Expected %v but got %v", tt.expected, result)
+			}
+			if local := result.In(loc); local.Hour() != 0 || local.Minute() != 0 {
+				t.Errorf("Expected midnight in Eastern Time, got %v", local)
+			}
+		})
+	}
+}